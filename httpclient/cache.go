@@ -0,0 +1,449 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cleverse/go-utilities/logger"
+	"github.com/Cleverse/go-utilities/logger/slogx"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultCacheRevalidateTTL = 24 * time.Hour
+
+	// maximum heuristic freshness lifetime of responses with Last-Modified but without explicit expiration.
+	maxCacheHeuristicLifetime = 24 * time.Hour
+)
+
+// Cache statuses of a request, reported in the debug log.
+const (
+	cacheStatusBypass      = "bypass"
+	cacheStatusHit         = "hit"
+	cacheStatusMiss        = "miss"
+	cacheStatusRevalidated = "revalidated"
+)
+
+// heuristically cacheable status codes, https://www.rfc-editor.org/rfc/rfc9110#section-15.1
+var cacheableStatusCodes = []int{
+	fasthttp.StatusOK,
+	fasthttp.StatusNonAuthoritativeInfo,
+	fasthttp.StatusNoContent,
+	fasthttp.StatusPartialContent,
+	fasthttp.StatusMultipleChoices,
+	fasthttp.StatusMovedPermanently,
+	fasthttp.StatusPermanentRedirect,
+	fasthttp.StatusNotFound,
+	fasthttp.StatusMethodNotAllowed,
+	fasthttp.StatusGone,
+	fasthttp.StatusRequestURITooLong,
+	fasthttp.StatusNotImplemented,
+}
+
+// skipped response headers of cached responses, they are connection specific or set from the body.
+var cacheSkipHeaders = []string{
+	fasthttp.HeaderConnection,
+	fasthttp.HeaderContentLength,
+	fasthttp.HeaderTransferEncoding,
+	fasthttp.HeaderKeepAlive,
+}
+
+// request headers carrying credentials of the caller, responses are cached per value of these headers.
+var defaultCacheCredentialHeaders = []string{
+	fasthttp.HeaderAuthorization,
+	fasthttp.HeaderProxyAuthorization,
+	fasthttp.HeaderCookie,
+	"X-Api-Key",
+}
+
+// CacheConfig is the HTTP cache of GET responses (RFC 9111), it's disabled if Store is nil.
+//
+// Fresh responses (Cache-Control max-age, Expires, or heuristic from Last-Modified) are replied from the store without
+// sending the request. Stale responses with ETag or Last-Modified are revalidated with a conditional request,
+// and the cached response is replied if the server responds 304 Not Modified.
+// Responses with Cache-Control no-store are not cached, and requests with Cache-Control no-store or no-cache bypass
+// or revalidate the cache.
+//
+// Responses of requests with credential headers (Authorization, Proxy-Authorization, Cookie, X-Api-Key and
+// CredentialHeaders, including the ones set by Config.Headers) are cached per credential, so they are never replied to
+// requests with another credential.
+type CacheConfig struct {
+	// Store of the cached responses, e.g. NewMemoryCacheStore or redis.NewCacheStore.
+	Store CacheStore
+
+	// Shared marks the store as a shared cache, e.g. a redis store used by many processes or users.
+	// A shared cache never stores Cache-Control private responses or responses with Set-Cookie, stores responses of
+	// requests with credential headers only if they are explicitly public (public, s-maxage or must-revalidate),
+	// and honors s-maxage.
+	Shared bool
+
+	// Additional request headers carrying credentials, e.g. a custom API key header.
+	CredentialHeaders []string
+
+	// How long responses with ETag or Last-Modified are kept after they become stale for revalidation. Default is 24h.
+	RevalidateTTL time.Duration
+}
+
+type responseCache struct {
+	config            CacheConfig
+	credentialHeaders []string
+	now               func() time.Time
+}
+
+func newResponseCache(config CacheConfig) *responseCache {
+	if config.Store == nil {
+		return nil
+	}
+	if config.RevalidateTTL <= 0 {
+		config.RevalidateTTL = defaultCacheRevalidateTTL
+	}
+	return &responseCache{
+		config:            config,
+		credentialHeaders: append(slices.Clone(defaultCacheCredentialHeaders), config.CredentialHeaders...),
+		now:               time.Now,
+	}
+}
+
+func (c *responseCache) middleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			state := requestStateFromContext(ctx)
+			reqCacheControl := parseCacheControl(req.Header.Peek(fasthttp.HeaderCacheControl))
+			if !req.Header.IsGet() || req.IsBodyStream() || len(req.Body()) > 0 || resp.StreamBody || reqCacheControl.noStore {
+				state.cache = cacheStatusBypass
+				return next(ctx, req, resp)
+			}
+
+			key := c.cacheKey(req)
+			entry, found := c.load(ctx, key, req)
+			if found && !reqCacheControl.noCache && entry.isFresh(c.now(), reqCacheControl, c.config.Shared) {
+				state.cache = cacheStatusHit
+				entry.writeTo(resp, c.now())
+				return nil
+			}
+			if found {
+				if etag := entry.Header.Get(fasthttp.HeaderETag); etag != "" {
+					req.Header.Set(fasthttp.HeaderIfNoneMatch, etag)
+				}
+				if lastModified := entry.Header.Get(fasthttp.HeaderLastModified); lastModified != "" {
+					req.Header.Set(fasthttp.HeaderIfModifiedSince, lastModified)
+				}
+			}
+
+			requestTime := c.now()
+			if err := next(ctx, req, resp); err != nil {
+				return err
+			}
+			responseTime := c.now()
+
+			if found && resp.StatusCode() == fasthttp.StatusNotModified {
+				state.cache = cacheStatusRevalidated
+				entry.revalidate(resp, requestTime, responseTime)
+				if c.config.Shared && len(entry.Header.Values(fasthttp.HeaderSetCookie)) > 0 {
+					c.delete(ctx, key)
+				} else {
+					c.store(ctx, key, entry)
+				}
+				entry.writeTo(resp, responseTime)
+				return nil
+			}
+
+			state.cache = cacheStatusMiss
+			if entry, ok := newCacheEntry(req, resp, requestTime, responseTime, c.config.Shared, c.hasCredentials(req)); ok {
+				c.store(ctx, key, entry)
+			} else if found {
+				c.delete(ctx, key)
+			}
+			return nil
+		}
+	}
+}
+
+// cacheKey returns the store key of the request. The key of a request with credential headers includes
+// the hash of the headers, so responses are not shared between credentials.
+func (c *responseCache) cacheKey(req *fasthttp.Request) string {
+	key := "GET " + req.URI().String()
+	if !c.hasCredentials(req) {
+		return key
+	}
+
+	hash := sha256.New()
+	for _, name := range c.credentialHeaders {
+		hash.Write([]byte(name + ":"))
+		hash.Write(req.Header.Peek(name))
+		hash.Write([]byte("\n"))
+	}
+	return key + " auth:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// hasCredentials reports whether the request has any of the credential headers.
+func (c *responseCache) hasCredentials(req *fasthttp.Request) bool {
+	return slices.ContainsFunc(c.credentialHeaders, func(name string) bool {
+		return len(req.Header.Peek(name)) > 0
+	})
+}
+
+// load returns the cached response of the key, or false if it's not found or doesn't match the Vary headers of the request.
+// Store errors are logged and treated as cache misses.
+func (c *responseCache) load(ctx context.Context, key string, req *fasthttp.Request) (*cacheEntry, bool) {
+	value, ok, err := c.config.Store.Get(ctx, key)
+	if err != nil {
+		c.warn(ctx, "Failed to get cached response", key, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		c.warn(ctx, "Failed to decode cached response", key, err)
+		return nil, false
+	}
+	for name, value := range entry.Vary {
+		if string(req.Header.Peek(name)) != value {
+			return nil, false
+		}
+	}
+	return &entry, true
+}
+
+func (c *responseCache) store(ctx context.Context, key string, entry *cacheEntry) {
+	now := c.now()
+	ttl := entry.freshnessLifetime(c.config.Shared) - entry.currentAge(now)
+	if entry.hasValidators() {
+		ttl = max(ttl, 0) + c.config.RevalidateTTL
+	}
+	if ttl <= 0 {
+		c.delete(ctx, key)
+		return
+	}
+
+	value, err := json.Marshal(entry)
+	if err == nil {
+		err = c.config.Store.Set(ctx, key, value, ttl)
+	}
+	if err != nil {
+		c.warn(ctx, "Failed to store cached response", key, err)
+	}
+}
+
+func (c *responseCache) delete(ctx context.Context, key string) {
+	if err := c.config.Store.Delete(ctx, key); err != nil {
+		c.warn(ctx, "Failed to delete cached response", key, err)
+	}
+}
+
+// warn logs a store error, the cache is best-effort and store errors don't fail the request.
+func (c *responseCache) warn(ctx context.Context, msg, key string, err error) {
+	logger.WarnContext(ctx, msg,
+		slog.String("package", "httpclient"),
+		slog.String("key", key),
+		slogx.Error(err),
+	)
+}
+
+// cacheEntry is a cached response with the times needed to calculate its age.
+type cacheEntry struct {
+	StatusCode   int               `json:"status_code"`
+	Header       http.Header       `json:"header"`
+	Body         []byte            `json:"body"`
+	Vary         map[string]string `json:"vary,omitempty"`
+	RequestTime  time.Time         `json:"request_time"`
+	ResponseTime time.Time         `json:"response_time"`
+}
+
+// newCacheEntry returns the cache entry of the response, or false if the response can't be stored
+// (in a shared cache if shared is true), https://www.rfc-editor.org/rfc/rfc9111#section-3
+func newCacheEntry(req *fasthttp.Request, resp *fasthttp.Response, requestTime, responseTime time.Time, shared, credentials bool) (*cacheEntry, bool) {
+	if !slices.Contains(cacheableStatusCodes, resp.StatusCode()) {
+		return nil, false
+	}
+
+	entry := &cacheEntry{
+		StatusCode:   resp.StatusCode(),
+		Header:       make(http.Header),
+		Body:         append([]byte(nil), resp.Body()...),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+	resp.Header.VisitAll(func(key, value []byte) {
+		if !slices.Contains(cacheSkipHeaders, string(key)) {
+			entry.Header.Add(string(key), string(value))
+		}
+	})
+
+	cacheControl := entry.cacheControl()
+	if cacheControl.noStore {
+		return nil, false
+	}
+	if shared {
+		// responses setting cookies are specific to the caller, https://www.rfc-editor.org/rfc/rfc9111#section-7.3
+		if cacheControl.private || len(entry.Header.Values(fasthttp.HeaderSetCookie)) > 0 {
+			return nil, false
+		}
+		// https://www.rfc-editor.org/rfc/rfc9111#section-3.5
+		explicitlyPublic := cacheControl.public || cacheControl.sMaxAge >= 0 || cacheControl.mustRevalidate
+		if credentials && !explicitlyPublic {
+			return nil, false
+		}
+	}
+	for _, vary := range entry.Header.Values(fasthttp.HeaderVary) {
+		for name := range strings.SplitSeq(vary, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+			if name == "" {
+				continue
+			}
+			if entry.Vary == nil {
+				entry.Vary = make(map[string]string)
+			}
+			entry.Vary[name] = string(req.Header.Peek(name))
+		}
+	}
+	return entry, true
+}
+
+func (e *cacheEntry) cacheControl() cacheControl {
+	return parseCacheControl([]byte(strings.Join(e.Header.Values(fasthttp.HeaderCacheControl), ",")))
+}
+
+func (e *cacheEntry) hasValidators() bool {
+	return e.Header.Get(fasthttp.HeaderETag) != "" || e.Header.Get(fasthttp.HeaderLastModified) != ""
+}
+
+// date returns the Date header of the response, or the response time if it's missing.
+func (e *cacheEntry) date() time.Time {
+	if date, err := http.ParseTime(e.Header.Get(fasthttp.HeaderDate)); err == nil {
+		return date
+	}
+	return e.ResponseTime
+}
+
+// freshnessLifetime returns how long the response is fresh after it's generated by the server,
+// s-maxage is used only by shared caches, https://www.rfc-editor.org/rfc/rfc9111#section-4.2.1
+func (e *cacheEntry) freshnessLifetime(shared bool) time.Duration {
+	cacheControl := e.cacheControl()
+	if cacheControl.noCache {
+		return 0
+	}
+	if shared && cacheControl.sMaxAge >= 0 {
+		return cacheControl.sMaxAge
+	}
+	if cacheControl.maxAge >= 0 {
+		return cacheControl.maxAge
+	}
+	if expires := e.Header.Get(fasthttp.HeaderExpires); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// invalid Expires means already expired.
+			return 0
+		}
+		return expiresAt.Sub(e.date())
+	}
+	if lastModified, err := http.ParseTime(e.Header.Get(fasthttp.HeaderLastModified)); err == nil {
+		return min(e.date().Sub(lastModified)/10, maxCacheHeuristicLifetime)
+	}
+	return 0
+}
+
+// currentAge returns the age of the response, https://www.rfc-editor.org/rfc/rfc9111#section-4.2.3
+func (e *cacheEntry) currentAge(now time.Time) time.Duration {
+	apparentAge := max(e.ResponseTime.Sub(e.date()), 0)
+	var ageValue time.Duration
+	if age, err := strconv.Atoi(e.Header.Get(fasthttp.HeaderAge)); err == nil && age > 0 {
+		ageValue = time.Duration(age) * time.Second
+	}
+	correctedAgeValue := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	return max(apparentAge, correctedAgeValue) + now.Sub(e.ResponseTime)
+}
+
+func (e *cacheEntry) isFresh(now time.Time, reqCacheControl cacheControl, shared bool) bool {
+	age := e.currentAge(now)
+	if reqCacheControl.maxAge >= 0 && age > reqCacheControl.maxAge {
+		return false
+	}
+	return e.freshnessLifetime(shared) > age
+}
+
+// revalidate updates the entry with the headers of the 304 Not Modified response.
+func (e *cacheEntry) revalidate(resp *fasthttp.Response, requestTime, responseTime time.Time) {
+	updated := make(http.Header)
+	resp.Header.VisitAll(func(key, value []byte) {
+		if !slices.Contains(cacheSkipHeaders, string(key)) {
+			updated.Add(string(key), string(value))
+		}
+	})
+	for key, values := range updated {
+		e.Header[key] = values
+	}
+	if updated.Get(fasthttp.HeaderAge) == "" {
+		e.Header.Del(fasthttp.HeaderAge)
+	}
+	e.RequestTime = requestTime
+	e.ResponseTime = responseTime
+}
+
+// writeTo writes the cached response to resp with its current age.
+func (e *cacheEntry) writeTo(resp *fasthttp.Response, now time.Time) {
+	resp.Reset()
+	resp.SetStatusCode(e.StatusCode)
+	for key, values := range e.Header {
+		for _, value := range values {
+			resp.Header.Add(key, value)
+		}
+	}
+	resp.Header.Set(fasthttp.HeaderAge, strconv.Itoa(int(e.currentAge(now).Seconds())))
+	resp.SetBody(e.Body)
+}
+
+// cacheControl is the parsed Cache-Control header of a request or response.
+type cacheControl struct {
+	noStore        bool
+	noCache        bool
+	public         bool
+	private        bool
+	mustRevalidate bool
+
+	// max-age and s-maxage directives, -1 if they are not set.
+	maxAge  time.Duration
+	sMaxAge time.Duration
+}
+
+func parseCacheControl(value []byte) cacheControl {
+	cc := cacheControl{maxAge: -1, sMaxAge: -1}
+	for directive := range strings.SplitSeq(string(value), ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			cc.noCache = true
+		case "public":
+			cc.public = true
+		case "private":
+			cc.private = true
+		case "must-revalidate":
+			cc.mustRevalidate = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(arg, `"`)); err == nil && seconds >= 0 {
+				cc.maxAge = time.Duration(seconds) * time.Second
+			}
+		case "s-maxage":
+			if seconds, err := strconv.Atoi(strings.Trim(arg, `"`)); err == nil && seconds >= 0 {
+				cc.sMaxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return cc
+}
@@ -0,0 +1,346 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func newCacheTestServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	var count atomic.Int32
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		n := count.Add(1)
+		switch string(ctx.Path()) {
+		case "/fresh":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "public, max-age=60")
+		case "/no-store":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "no-store")
+		case "/etag":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "no-cache")
+			ctx.Response.Header.Set(fasthttp.HeaderETag, `"v1"`)
+			if string(ctx.Request.Header.Peek(fasthttp.HeaderIfNoneMatch)) == `"v1"` {
+				ctx.SetStatusCode(fasthttp.StatusNotModified)
+				return
+			}
+		case "/last-modified":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "max-age=0")
+			ctx.Response.Header.Set(fasthttp.HeaderLastModified, lastModified)
+			if string(ctx.Request.Header.Peek(fasthttp.HeaderIfModifiedSince)) == lastModified {
+				ctx.SetStatusCode(fasthttp.StatusNotModified)
+				return
+			}
+		case "/vary":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "max-age=60")
+			ctx.Response.Header.Set(fasthttp.HeaderVary, "Accept-Language")
+		case "/not-found":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "max-age=60")
+			ctx.SetStatusCode(fasthttp.StatusNotFound)
+		case "/error":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "max-age=60")
+			ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		case "/auth":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "max-age=60")
+		case "/private":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "private, max-age=60")
+		case "/public":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "public, max-age=60")
+		case "/s-maxage":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "max-age=0, s-maxage=60")
+		case "/set-cookie":
+			ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "public, max-age=60")
+			ctx.Response.Header.Set(fasthttp.HeaderSetCookie, "session="+strconv.Itoa(int(n)))
+		}
+		ctx.WriteString(strconv.Itoa(int(n)))
+		if auth := ctx.Request.Header.Peek(fasthttp.HeaderAuthorization); len(auth) > 0 {
+			ctx.WriteString(":" + string(auth))
+		}
+	})
+
+	return baseURL, &count
+}
+
+// fakeClock is a manually advanced clock of the response cache.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newCacheTestClient(t *testing.T, shared bool) (*Client, *atomic.Int32, *fakeClock) {
+	t.Helper()
+
+	baseURL, count := newCacheTestServer(t)
+	client, err := New(baseURL, Config{Cache: CacheConfig{Store: NewMemoryCacheStore(0), Shared: shared}})
+	require.NoError(t, err)
+
+	clock := &fakeClock{now: time.Now()}
+	client.cache.now = clock.Now
+	return client, count, clock
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fresh", func(t *testing.T) {
+		client, count, clock := newCacheTestClient(t, false)
+
+		for range 3 {
+			resp, err := client.Get(ctx, "/fresh", RequestOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "1", string(resp.Body()))
+		}
+		assert.Equal(t, int32(1), count.Load())
+
+		clock.Advance(30 * time.Second)
+		resp, err := client.Get(ctx, "/fresh", RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "1", string(resp.Body()))
+		// the Date header has a second resolution, the apparent age may add up to a second.
+		age, err := strconv.Atoi(string(resp.Header.Peek(fasthttp.HeaderAge)))
+		require.NoError(t, err)
+		assert.InDelta(t, 30, age, 1)
+
+		clock.Advance(31 * time.Second)
+		resp, err = client.Get(ctx, "/fresh", RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "2", string(resp.Body()))
+	})
+
+	t.Run("revalidate etag", func(t *testing.T) {
+		client, count, _ := newCacheTestClient(t, false)
+
+		for range 3 {
+			resp, err := client.Get(ctx, "/etag", RequestOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+			assert.Equal(t, "1", string(resp.Body()))
+		}
+		assert.Equal(t, int32(3), count.Load())
+	})
+
+	t.Run("revalidate last modified", func(t *testing.T) {
+		client, count, _ := newCacheTestClient(t, false)
+
+		for range 2 {
+			resp, err := client.Get(ctx, "/last-modified", RequestOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+			assert.Equal(t, "1", string(resp.Body()))
+		}
+		assert.Equal(t, int32(2), count.Load())
+	})
+
+	t.Run("not cached", func(t *testing.T) {
+		client, count, _ := newCacheTestClient(t, false)
+
+		for _, path := range []string{"/no-store", "/error"} {
+			for range 2 {
+				_, err := client.Get(ctx, path, RequestOptions{})
+				require.NoError(t, err)
+			}
+		}
+		for range 2 {
+			_, err := client.Post(ctx, "/fresh", RequestOptions{})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(6), count.Load())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client, count, _ := newCacheTestClient(t, false)
+
+		for range 2 {
+			resp, err := client.Get(ctx, "/not-found", RequestOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusNotFound, resp.StatusCode())
+		}
+		assert.Equal(t, int32(1), count.Load())
+	})
+
+	t.Run("request cache control", func(t *testing.T) {
+		client, count, _ := newCacheTestClient(t, false)
+
+		_, err := client.Get(ctx, "/fresh", RequestOptions{})
+		require.NoError(t, err)
+
+		resp, err := client.Get(ctx, "/fresh", RequestOptions{Header: map[string]string{"Cache-Control": "no-cache"}})
+		require.NoError(t, err)
+		assert.Equal(t, "2", string(resp.Body()))
+
+		resp, err = client.Get(ctx, "/fresh", RequestOptions{Header: map[string]string{"Cache-Control": "no-store"}})
+		require.NoError(t, err)
+		assert.Equal(t, "3", string(resp.Body()))
+
+		resp, err = client.Get(ctx, "/fresh", RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "2", string(resp.Body()))
+		assert.Equal(t, int32(3), count.Load())
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		client, count, _ := newCacheTestClient(t, false)
+
+		get := func(language string) string {
+			resp, err := client.Get(ctx, "/vary", RequestOptions{Header: map[string]string{"Accept-Language": language}})
+			require.NoError(t, err)
+			return string(resp.Body())
+		}
+		assert.Equal(t, "1", get("en"))
+		assert.Equal(t, "1", get("en"))
+		assert.Equal(t, "2", get("th"))
+		assert.Equal(t, int32(2), count.Load())
+	})
+
+	getAs := func(t *testing.T, client *Client, path, auth string) string {
+		t.Helper()
+		resp, err := client.Get(ctx, path, RequestOptions{Header: map[string]string{"Authorization": auth}})
+		require.NoError(t, err)
+		return string(resp.Body())
+	}
+
+	t.Run("authorization", func(t *testing.T) {
+		client, count, _ := newCacheTestClient(t, false)
+
+		assert.Equal(t, "1:alice", getAs(t, client, "/auth", "alice"))
+		assert.Equal(t, "2:bob", getAs(t, client, "/auth", "bob"))
+		assert.Equal(t, "1:alice", getAs(t, client, "/auth", "alice"))
+		assert.Equal(t, "2:bob", getAs(t, client, "/auth", "bob"))
+		assert.Equal(t, int32(2), count.Load())
+	})
+
+	t.Run("credential headers", func(t *testing.T) {
+		baseURL, count := newCacheTestServer(t)
+		client, err := New(baseURL, Config{
+			Headers: map[string]string{"X-Token": "default"},
+			Cache:   CacheConfig{Store: NewMemoryCacheStore(0), CredentialHeaders: []string{"X-Token"}},
+		})
+		require.NoError(t, err)
+
+		get := func(header map[string]string) string {
+			t.Helper()
+			resp, err := client.Get(ctx, "/auth", RequestOptions{Header: header})
+			require.NoError(t, err)
+			return string(resp.Body())
+		}
+		assert.Equal(t, "1", get(nil))
+		assert.Equal(t, "2", get(map[string]string{"Cookie": "session=alice"}))
+		assert.Equal(t, "3", get(map[string]string{"Cookie": "session=bob"}))
+		assert.Equal(t, "4", get(map[string]string{"Proxy-Authorization": "alice"}))
+		assert.Equal(t, "5", get(map[string]string{"X-Api-Key": "alice"}))
+		assert.Equal(t, "6", get(map[string]string{"X-Token": "alice"}))
+		assert.Equal(t, "2", get(map[string]string{"Cookie": "session=alice"}))
+		assert.Equal(t, "5", get(map[string]string{"X-Api-Key": "alice"}))
+		assert.Equal(t, "6", get(map[string]string{"X-Token": "alice"}))
+		assert.Equal(t, "1", get(nil))
+		assert.Equal(t, int32(6), count.Load())
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		client, count, clock := newCacheTestClient(t, true)
+
+		// private responses and responses of authorized requests without explicit public are not stored.
+		assert.Equal(t, "1", getAs(t, client, "/private", ""))
+		assert.Equal(t, "2", getAs(t, client, "/private", ""))
+		assert.Equal(t, "3:alice", getAs(t, client, "/auth", "alice"))
+		assert.Equal(t, "4:alice", getAs(t, client, "/auth", "alice"))
+		assert.Equal(t, "5:alice", getAs(t, client, "/public", "alice"))
+		assert.Equal(t, "5:alice", getAs(t, client, "/public", "alice"))
+		assert.Equal(t, "6:bob", getAs(t, client, "/public", "bob"))
+
+		// s-maxage overrides max-age.
+		assert.Equal(t, "7", getAs(t, client, "/s-maxage", ""))
+		clock.Advance(30 * time.Second)
+		assert.Equal(t, "7", getAs(t, client, "/s-maxage", ""))
+		assert.Equal(t, int32(7), count.Load())
+
+		// responses with Set-Cookie are not stored.
+		for _, n := range []string{"8", "9"} {
+			resp, err := client.Get(ctx, "/set-cookie", RequestOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, n, string(resp.Body()))
+			assert.Contains(t, string(resp.Header.Peek(fasthttp.HeaderSetCookie)), "session="+n)
+		}
+	})
+
+	t.Run("private", func(t *testing.T) {
+		client, count, clock := newCacheTestClient(t, false)
+
+		assert.Equal(t, "1", getAs(t, client, "/private", ""))
+		assert.Equal(t, "1", getAs(t, client, "/private", ""))
+
+		// s-maxage is ignored by private caches.
+		assert.Equal(t, "2", getAs(t, client, "/s-maxage", ""))
+		clock.Advance(time.Second)
+		assert.Equal(t, "3", getAs(t, client, "/s-maxage", ""))
+		assert.Equal(t, int32(3), count.Load())
+	})
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCacheStore(2)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+
+	// "a" is the most recently used, "b" is evicted.
+	value, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	require.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute))
+	assert.Equal(t, 2, store.Len())
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	_, ok, _ = store.Get(ctx, "a")
+	assert.False(t, ok)
+
+	require.NoError(t, store.Set(ctx, "expired", []byte("4"), -time.Second))
+	_, ok, _ = store.Get(ctx, "expired")
+	assert.False(t, ok)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		value string
+		want  cacheControl
+	}{
+		{value: "", want: cacheControl{maxAge: -1, sMaxAge: -1}},
+		{value: "max-age=60", want: cacheControl{maxAge: time.Minute, sMaxAge: -1}},
+		{value: `public, Max-Age="10", no-cache`, want: cacheControl{noCache: true, public: true, maxAge: 10 * time.Second, sMaxAge: -1}},
+		{value: "no-store, max-age=invalid", want: cacheControl{noStore: true, maxAge: -1, sMaxAge: -1}},
+		{value: "private, must-revalidate, s-maxage=5", want: cacheControl{private: true, mustRevalidate: true, maxAge: -1, sMaxAge: 5 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseCacheControl([]byte(tt.value)))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultMemoryCacheMaxEntries = 1024
+
+// CacheStore stores the cached responses of Client, see CacheConfig.
+// The values are opaque encoded responses, and the store may drop them at any time (e.g. on eviction).
+//
+// The redis package provides an implementation backed by a redis client, see redis.NewCacheStore.
+// Set CacheConfig.Shared if the store is shared between processes or users.
+type CacheStore interface {
+	// Get returns the value of the key, or false if it's not found or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores the value of the key for the ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the key, it's not an error if the key is not found.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCacheStore is a thread-safe in-memory CacheStore with LRU eviction.
+type MemoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCacheStore creates an in-memory store that keeps at most maxEntries values, the least recently used value
+// is evicted when it's full. Default maxEntries is 1024 if maxEntries <= 0.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryCacheMaxEntries
+	}
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value of the key, or false if it's not found or expired. The value must not be modified.
+func (s *MemoryCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		s.removeElement(elem)
+		return nil, false, nil
+	}
+	s.ll.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores the value of the key for the ttl, the value must not be modified after that.
+func (s *MemoryCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := s.items[key]; ok {
+		entry := elem.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.ll.MoveToFront(elem)
+		return nil
+	}
+
+	s.items[key] = s.ll.PushFront(&memoryCacheEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.ll.Len() > s.maxEntries {
+		s.removeElement(s.ll.Back())
+	}
+	return nil
+}
+
+// Delete removes the key.
+func (s *MemoryCacheStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.removeElement(elem)
+	}
+	return nil
+}
+
+// Len returns the number of values in the store, including expired values that are not removed yet.
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+func (s *MemoryCacheStore) removeElement(elem *list.Element) {
+	s.ll.Remove(elem)
+	delete(s.items, elem.Value.(*memoryCacheEntry).key)
+}
@@ -0,0 +1,275 @@
+package httpclient
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/Cleverse/go-utilities/logger"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultCircuitMinRequests         = 10
+	defaultCircuitWindow              = 60 * time.Second
+	defaultCircuitCoolDown            = 30 * time.Second
+	defaultCircuitHalfOpenMaxRequests = 1
+)
+
+// ErrCircuitOpen is returned when the circuit breaker of the host is open and the request is rejected without being sent.
+//
+// inherited error from errs.Unavailable,
+// so errors.Is(err, errs.Unavailable) == true
+var ErrCircuitOpen = errors.Wrap(errs.Unavailable, "circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed allows all requests and counts the failures.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects all requests with ErrCircuitOpen until the cool-down is passed.
+	CircuitOpen
+
+	// CircuitHalfOpen allows a limited number of trial requests to check if the host is recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig is the configuration of per-host circuit breaker.
+// Circuit breaker is disabled by default (FailureRatio <= 0).
+//
+// A request is counted as a failure if it returns an error or the response status code is 5xx.
+type CircuitBreakerConfig struct {
+	// Ratio of failed requests in the window to open the circuit, between 0 and 1. Circuit breaker is disabled if FailureRatio <= 0.
+	FailureRatio float64
+
+	// Minimum number of requests in the window before the failure ratio is evaluated. Default is 10.
+	MinRequests int
+
+	// Duration of the window to count the requests in closed state. Default is 60s.
+	Window time.Duration
+
+	// Duration of open state before trial requests are allowed (half-open state). Default is 30s.
+	CoolDown time.Duration
+
+	// Maximum number of trial requests in half-open state. The circuit is closed when all of them succeed. Default is 1.
+	HalfOpenMaxRequests int
+}
+
+func (c CircuitBreakerConfig) withDefaults() CircuitBreakerConfig {
+	if c.MinRequests <= 0 {
+		c.MinRequests = defaultCircuitMinRequests
+	}
+	if c.Window <= 0 {
+		c.Window = defaultCircuitWindow
+	}
+	if c.CoolDown <= 0 {
+		c.CoolDown = defaultCircuitCoolDown
+	}
+	if c.HalfOpenMaxRequests <= 0 {
+		c.HalfOpenMaxRequests = defaultCircuitHalfOpenMaxRequests
+	}
+	return c
+}
+
+// circuitBreakers holds the circuit breaker of each host.
+type circuitBreakers struct {
+	config CircuitBreakerConfig
+	mu     sync.Mutex
+	hosts  map[string]*circuitBreaker
+}
+
+func newCircuitBreakers(config CircuitBreakerConfig) *circuitBreakers {
+	if config.FailureRatio <= 0 {
+		return nil
+	}
+	return &circuitBreakers{
+		config: config.withDefaults(),
+		hosts:  make(map[string]*circuitBreaker),
+	}
+}
+
+func (c *circuitBreakers) host(host string) *circuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cb, ok := c.hosts[host]
+	if !ok {
+		cb = &circuitBreaker{
+			host:        host,
+			config:      c.config,
+			windowStart: time.Now(),
+		}
+		c.hosts[host] = cb
+	}
+	return cb
+}
+
+// middleware returns a middleware that rejects requests to the hosts with open circuit.
+func (c *circuitBreakers) middleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			cb := c.host(string(req.URI().Host()))
+
+			generation, err := cb.allow(ctx)
+			if err != nil {
+				return err
+			}
+
+			err = next(ctx, req, resp)
+			switch {
+			case err != nil && (ctx.Err() != nil || errors.Is(err, errs.RateLimitExceeded)):
+				// cancelled by the caller or rejected by a client-side limit, the host health is unknown.
+				cb.release(generation)
+			case err != nil || resp.StatusCode() >= fasthttp.StatusInternalServerError:
+				cb.record(ctx, generation, false)
+			default:
+				cb.record(ctx, generation, true)
+			}
+			return err
+		}
+	}
+}
+
+// circuitBreaker is a thread-safe circuit breaker of a host.
+type circuitBreaker struct {
+	host   string
+	config CircuitBreakerConfig
+
+	mu       sync.Mutex
+	state    CircuitState
+	openedAt time.Time
+
+	// generation is increased on every state change, so results of requests allowed in the previous state are ignored.
+	generation uint64
+
+	// counts of the current window (closed state) or trial requests (half-open state).
+	windowStart time.Time
+	requests    int
+	failures    int
+	successes   int
+	inFlight    int
+}
+
+// allow returns the generation of the current state if the request is allowed, or ErrCircuitOpen otherwise.
+func (cb *circuitBreaker) allow(ctx context.Context) (generation uint64, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	switch cb.state {
+	case CircuitClosed:
+		if now.Sub(cb.windowStart) >= cb.config.Window {
+			cb.resetCounts(now)
+		}
+	case CircuitOpen:
+		if now.Sub(cb.openedAt) < cb.config.CoolDown {
+			return 0, errors.Wrapf(ErrCircuitOpen, "host: %s", cb.host)
+		}
+		cb.setState(ctx, CircuitHalfOpen, now)
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.inFlight >= cb.config.HalfOpenMaxRequests {
+			return 0, errors.Wrapf(ErrCircuitOpen, "host: %s, half-open trial requests are in-flight", cb.host)
+		}
+	}
+
+	cb.inFlight++
+	return cb.generation, nil
+}
+
+// record records the result of the request allowed in the given generation.
+func (cb *circuitBreaker) record(ctx context.Context, generation uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+	cb.inFlight--
+
+	now := time.Now()
+	switch cb.state {
+	case CircuitClosed:
+		cb.requests++
+		if !success {
+			cb.failures++
+		}
+		if cb.requests >= cb.config.MinRequests && float64(cb.failures)/float64(cb.requests) >= cb.config.FailureRatio {
+			cb.setState(ctx, CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		if !success {
+			cb.setState(ctx, CircuitOpen, now)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.config.HalfOpenMaxRequests {
+			cb.setState(ctx, CircuitClosed, now)
+		}
+	}
+}
+
+// release releases the request allowed in the given generation without recording the result.
+func (cb *circuitBreaker) release(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation == cb.generation {
+		cb.inFlight--
+	}
+}
+
+// currentState returns the current state of the circuit breaker.
+func (cb *circuitBreaker) currentState() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// setState changes the state and logs the transition. the caller must hold the lock.
+func (cb *circuitBreaker) setState(ctx context.Context, state CircuitState, now time.Time) {
+	from := cb.state
+	failures, requests := cb.failures, cb.requests
+
+	cb.state = state
+	cb.generation++
+	cb.inFlight = 0
+	cb.resetCounts(now)
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+
+	logger.WarnContext(ctx, "Circuit breaker state changed",
+		slog.String("package", "httpclient"),
+		slog.String("host", cb.host),
+		slog.String("from", from.String()),
+		slog.String("to", state.String()),
+		slog.Int("failures", failures),
+		slog.Int("requests", requests),
+	)
+}
+
+func (cb *circuitBreaker) resetCounts(now time.Time) {
+	cb.windowStart = now
+	cb.requests = 0
+	cb.failures = 0
+	cb.successes = 0
+}
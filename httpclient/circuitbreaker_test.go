@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		healthy atomic.Bool
+		count   atomic.Int32
+	)
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		count.Add(1)
+		if !healthy.Load() {
+			ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		}
+	})
+
+	host := strings.TrimPrefix(baseURL, "http://")
+	client, err := New(baseURL, Config{
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureRatio: 0.5,
+			MinRequests:  4,
+			CoolDown:     100 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		resp, err := client.Get(ctx, "/", RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, fasthttp.StatusInternalServerError, resp.StatusCode())
+	}
+	assert.Equal(t, CircuitOpen, client.CircuitState(host))
+
+	// fail fast without sending the request.
+	_, err = client.Get(ctx, "/", RequestOptions{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorIs(t, err, errs.Unavailable)
+	assert.Equal(t, int32(4), count.Load())
+
+	// trial request after cool-down fails, the circuit is opened again.
+	time.Sleep(150 * time.Millisecond)
+	_, err = client.Get(ctx, "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, CircuitOpen, client.CircuitState(host))
+
+	// trial request after cool-down succeeds, the circuit is closed.
+	healthy.Store(true)
+	time.Sleep(150 * time.Millisecond)
+	resp, err := client.Get(ctx, "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+	assert.Equal(t, CircuitClosed, client.CircuitState(host))
+}
+
+func TestCircuitBreakerRateLimited(t *testing.T) {
+	var count atomic.Int32
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		count.Add(1)
+	})
+
+	host := strings.TrimPrefix(baseURL, "http://")
+	client, err := New(baseURL, Config{
+		RateLimit: RateLimitConfig{RequestsPerSecond: 1},
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureRatio: 0.5,
+			MinRequests:  4,
+		},
+	})
+	require.NoError(t, err)
+
+	// the first request is allowed by the burst, the rest are rejected by the rate limit before they're sent.
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := client.Get(ctx, "/", RequestOptions{})
+		cancel()
+		if i == 0 {
+			require.NoError(t, err)
+			continue
+		}
+		assert.ErrorIs(t, err, errs.RateLimitExceeded)
+	}
+	assert.Equal(t, int32(1), count.Load())
+
+	// client-side rejections are not host failures.
+	assert.Equal(t, CircuitClosed, client.CircuitState(host))
+	time.Sleep(time.Second)
+	_, err = client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), count.Load())
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	cb := newCircuitBreakers(CircuitBreakerConfig{
+		FailureRatio: 1,
+		MinRequests:  1,
+		CoolDown:     time.Millisecond,
+	}).host("example.com")
+	ctx := context.Background()
+
+	generation, err := cb.allow(ctx)
+	require.NoError(t, err)
+	cb.record(ctx, generation, false)
+	assert.Equal(t, CircuitOpen, cb.currentState())
+
+	time.Sleep(5 * time.Millisecond)
+
+	// only one trial request is allowed in half-open state.
+	generation, err = cb.allow(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, CircuitHalfOpen, cb.currentState())
+
+	_, err = cb.allow(ctx)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	cb.record(ctx, generation, true)
+	assert.Equal(t, CircuitClosed, cb.currentState())
+
+	// result of a request allowed in the previous state is ignored.
+	cb.record(ctx, generation, false)
+	assert.Equal(t, CircuitClosed, cb.currentState())
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	cb := newCircuitBreakers(CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  2,
+		Window:       50 * time.Millisecond,
+	}).host("example.com")
+	ctx := context.Background()
+
+	generation, err := cb.allow(ctx)
+	require.NoError(t, err)
+	cb.record(ctx, generation, false)
+
+	// the failure is expired with the window.
+	time.Sleep(60 * time.Millisecond)
+	generation, err = cb.allow(ctx)
+	require.NoError(t, err)
+	cb.record(ctx, generation, true)
+	assert.Equal(t, CircuitClosed, cb.currentState())
+}
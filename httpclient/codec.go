@@ -0,0 +1,213 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/vmihailenco/msgpack"
+)
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeXML     = "application/xml"
+	ContentTypeMsgpack = "application/msgpack"
+	ContentTypeForm    = "application/x-www-form-urlencoded"
+	ContentTypeText    = "text/plain"
+)
+
+// Codec encodes and decodes request and response bodies of a content type.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSONCodec encodes and decodes bodies with encoding/json.
+	JSONCodec Codec = jsonCodec{}
+
+	// XMLCodec encodes and decodes bodies with encoding/xml.
+	XMLCodec Codec = xmlCodec{}
+
+	// MsgpackCodec encodes and decodes bodies with msgpack.
+	MsgpackCodec Codec = msgpackCodec{}
+
+	// FormCodec encodes url.Values, map[string][]string and map[string]string as url-encoded form,
+	// and decodes into *url.Values, *map[string][]string and *map[string]string.
+	FormCodec Codec = formCodec{}
+
+	// TextCodec encodes string, []byte and fmt.Stringer as plain text, and decodes into *string and *[]byte.
+	TextCodec Codec = textCodec{}
+)
+
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{
+	m: map[string]Codec{
+		ContentTypeJSON:           JSONCodec,
+		ContentTypeXML:            XMLCodec,
+		"text/xml":                XMLCodec,
+		ContentTypeMsgpack:        MsgpackCodec,
+		"application/x-msgpack":   MsgpackCodec,
+		"application/vnd.msgpack": MsgpackCodec,
+		ContentTypeForm:           FormCodec,
+		ContentTypeText:           TextCodec,
+	},
+}
+
+// RegisterCodec registers the codec of the content type (e.g. "application/cbor") for all clients,
+// it replaces the codec already registered for the content type. Parameters of the content type are ignored.
+func RegisterCodec(contentType string, codec Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.m[mediaType(contentType)] = codec
+}
+
+// LookupCodec returns the codec registered for the content type. Parameters of the content type (e.g. charset) are ignored,
+// and content types with a +json or +xml suffix (e.g. "application/problem+json") fall back to the JSON or XML codec.
+func LookupCodec(contentType string) (Codec, bool) {
+	contentType = mediaType(contentType)
+
+	codecs.RLock()
+	codec, ok := codecs.m[contentType]
+	codecs.RUnlock()
+	if ok {
+		return codec, true
+	}
+
+	switch {
+	case strings.HasSuffix(contentType, "+json"):
+		return LookupCodec(ContentTypeJSON)
+	case strings.HasSuffix(contentType, "+xml"):
+		return LookupCodec(ContentTypeXML)
+	}
+	return nil, false
+}
+
+// mediaType returns the lowercase media type of the content type without parameters.
+func mediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	contentType, _, _ = strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// marshalBody encodes the value with the codec of the content type.
+func marshalBody(contentType string, v any) ([]byte, error) {
+	codec, ok := LookupCodec(contentType)
+	if !ok {
+		return nil, errors.Errorf("unsupported content type: %s", contentType)
+	}
+	body, err := codec.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't marshal %s body", contentType)
+	}
+	return body, nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return data, errors.WithStack(err)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return errors.WithStack(json.Unmarshal(data, v))
+}
+
+type xmlCodec struct{}
+
+func (xmlCodec) Marshal(v any) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	return data, errors.WithStack(err)
+}
+
+func (xmlCodec) Unmarshal(data []byte, v any) error {
+	return errors.WithStack(xml.Unmarshal(data, v))
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	data, err := msgpack.Marshal(v)
+	return data, errors.WithStack(err)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return errors.WithStack(msgpack.Unmarshal(data, v))
+}
+
+type formCodec struct{}
+
+func (formCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case url.Values:
+		return []byte(v.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(v).Encode()), nil
+	case map[string]string:
+		values := make(url.Values, len(v))
+		for key, value := range v {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), nil
+	default:
+		return nil, errors.Errorf("can't marshal %T as form", v)
+	}
+}
+
+func (formCodec) Unmarshal(data []byte, v any) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+	case *map[string][]string:
+		*v = values
+	case *map[string]string:
+		*v = make(map[string]string, len(values))
+		for key := range values {
+			(*v)[key] = values.Get(key)
+		}
+	default:
+		return errors.Errorf("can't unmarshal form into %T", v)
+	}
+	return nil
+}
+
+type textCodec struct{}
+
+func (textCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case fmt.Stringer:
+		return []byte(v.String()), nil
+	default:
+		return nil, errors.Errorf("can't marshal %T as text", v)
+	}
+}
+
+func (textCodec) Unmarshal(data []byte, v any) error {
+	switch v := v.(type) {
+	case *string:
+		*v = string(data)
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+	default:
+		return errors.Errorf("can't unmarshal text into %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package httpclient
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+type codecTestPayload struct {
+	Message string `json:"message" xml:"message" msgpack:"message"`
+}
+
+// newEchoTestClient returns a client of a server that echoes the request body and content type.
+func newEchoTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentTypeBytes(ctx.Request.Header.ContentType())
+		ctx.Write(ctx.PostBody())
+	})
+
+	client, err := New(baseURL)
+	require.NoError(t, err)
+	return client
+}
+
+func TestLookupCodec(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        Codec
+	}{
+		{contentType: "application/json", want: JSONCodec},
+		{contentType: "Application/JSON; charset=utf-8", want: JSONCodec},
+		{contentType: "application/problem+json", want: JSONCodec},
+		{contentType: "text/xml; charset=utf-8", want: XMLCodec},
+		{contentType: "application/atom+xml", want: XMLCodec},
+		{contentType: "application/x-msgpack", want: MsgpackCodec},
+		{contentType: "application/x-www-form-urlencoded", want: FormCodec},
+		{contentType: "text/plain; charset=utf-8", want: TextCodec},
+		{contentType: "image/png", want: nil},
+		{contentType: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			codec, ok := LookupCodec(tt.contentType)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, codec)
+		})
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	client := newEchoTestClient(t)
+	ctx := context.Background()
+
+	t.Run("struct", func(t *testing.T) {
+		for _, contentType := range []string{ContentTypeJSON, ContentTypeXML, ContentTypeMsgpack} {
+			t.Run(contentType, func(t *testing.T) {
+				resp, err := client.Post(ctx, "/", RequestOptions{
+					Payload:     codecTestPayload{Message: "hello"},
+					ContentType: contentType,
+				})
+				require.NoError(t, err)
+				assert.Equal(t, contentType, string(resp.Header.ContentType()))
+
+				var result codecTestPayload
+				require.NoError(t, resp.UnmarshalBody(&result))
+				assert.Equal(t, "hello", result.Message)
+			})
+		}
+	})
+
+	t.Run("form", func(t *testing.T) {
+		resp, err := client.Post(ctx, "/", RequestOptions{
+			Payload:     map[string]string{"a": "1", "b": "2"},
+			ContentType: ContentTypeForm,
+		})
+		require.NoError(t, err)
+
+		var values url.Values
+		require.NoError(t, resp.UnmarshalBody(&values))
+		assert.Equal(t, url.Values{"a": {"1"}, "b": {"2"}}, values)
+
+		var m map[string]string
+		require.NoError(t, resp.UnmarshalBody(&m))
+		assert.Equal(t, map[string]string{"a": "1", "b": "2"}, m)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		resp, err := client.Post(ctx, "/", RequestOptions{
+			Payload:     "plain text",
+			ContentType: "text/plain; charset=utf-8",
+		})
+		require.NoError(t, err)
+
+		var s string
+		require.NoError(t, resp.UnmarshalBody(&s))
+		assert.Equal(t, "plain text", s)
+
+		var b []byte
+		require.NoError(t, resp.UnmarshalBody(&b))
+		assert.Equal(t, []byte("plain text"), b)
+
+		var result codecTestPayload
+		assert.Error(t, resp.UnmarshalBody(&result))
+	})
+
+	t.Run("body with content type", func(t *testing.T) {
+		resp, err := client.Post(ctx, "/", RequestOptions{
+			Body:        []byte("<codecTestPayload><message>hello</message></codecTestPayload>"),
+			ContentType: ContentTypeXML,
+		})
+		require.NoError(t, err)
+
+		var result codecTestPayload
+		require.NoError(t, resp.UnmarshalBody(&result))
+		assert.Equal(t, "hello", result.Message)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := client.Post(ctx, "/", RequestOptions{
+			Payload:     codecTestPayload{Message: "hello"},
+			ContentType: "image/png",
+		})
+		assert.ErrorContains(t, err, "unsupported content type")
+
+		resp, err := client.Post(ctx, "/", RequestOptions{
+			Body:        []byte("png"),
+			ContentType: "image/png",
+		})
+		require.NoError(t, err)
+
+		var b []byte
+		assert.ErrorContains(t, resp.UnmarshalBody(&b), "unsupported content type")
+	})
+}
+
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v any) ([]byte, error) {
+	return []byte("UPPER:" + v.(string)), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v any) error {
+	*v.(*string) = string(data)
+	return nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	const contentType = "application/x-upper"
+	RegisterCodec(contentType, upperCodec{})
+	t.Cleanup(func() {
+		codecs.Lock()
+		delete(codecs.m, contentType)
+		codecs.Unlock()
+	})
+
+	client := newEchoTestClient(t)
+	resp, err := client.Post(context.Background(), "/", RequestOptions{
+		Payload:     "hello",
+		ContentType: contentType,
+	})
+	require.NoError(t, err)
+
+	var s string
+	require.NoError(t, resp.UnmarshalBody(&s))
+	assert.Equal(t, "UPPER:hello", s)
+}
@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// maxErrorBodySize is the maximum size of the response body snippet in StatusError.
+const maxErrorBodySize = 1024
+
+// StatusError is returned when the response status code is not successful (2xx).
+//
+// errors.Is matches StatusError against the errs package sentinels by status code:
+//   - 400, 422: errs.BadRequest
+//   - 401, 403: errs.Unauthorized
+//   - 404, 410: errs.NotFound
+//   - 408, 504: errs.Timeout
+//   - 429: errs.RateLimitExceeded
+//   - 502, 503: errs.Unavailable
+type StatusError struct {
+	StatusCode int
+	Method     string
+	URL        string
+
+	// Body is the response body, truncated to 1KB.
+	Body []byte
+
+	// Header is the response headers.
+	Header http.Header
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d, method: %s, url: %s, body: %q", e.StatusCode, e.Method, e.URL, e.Body)
+}
+
+// Is reports whether the status code matches the errs package sentinel.
+func (e *StatusError) Is(target error) bool {
+	sentinel := statusSentinel(e.StatusCode)
+	return sentinel != nil && errors.Is(sentinel, target)
+}
+
+func statusSentinel(statusCode int) error {
+	switch statusCode {
+	case fasthttp.StatusBadRequest, fasthttp.StatusUnprocessableEntity:
+		return errs.BadRequest
+	case fasthttp.StatusUnauthorized, fasthttp.StatusForbidden:
+		return errs.Unauthorized
+	case fasthttp.StatusNotFound, fasthttp.StatusGone:
+		return errs.NotFound
+	case fasthttp.StatusRequestTimeout, fasthttp.StatusGatewayTimeout:
+		return errs.Timeout
+	case fasthttp.StatusTooManyRequests:
+		return errs.RateLimitExceeded
+	case fasthttp.StatusBadGateway, fasthttp.StatusServiceUnavailable:
+		return errs.Unavailable
+	default:
+		return nil
+	}
+}
+
+func newStatusError(method string, resp *HttpResponse) *StatusError {
+	body, err := resp.BodyUncompressed()
+	if err != nil {
+		body = resp.Body()
+	}
+	if len(body) > maxErrorBodySize {
+		body = body[:maxErrorBodySize]
+	}
+
+	header := make(http.Header)
+	resp.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+
+	return &StatusError{
+		StatusCode: resp.StatusCode(),
+		Method:     method,
+		URL:        resp.URL,
+		Body:       append([]byte(nil), body...),
+		Header:     header,
+	}
+}
+
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= fasthttp.StatusOK && statusCode < fasthttp.StatusMultipleChoices
+}
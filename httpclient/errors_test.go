@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestStatusErrorIs(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		expected   error
+	}{
+		{fasthttp.StatusBadRequest, errs.BadRequest},
+		{fasthttp.StatusUnprocessableEntity, errs.BadRequest},
+		{fasthttp.StatusUnauthorized, errs.Unauthorized},
+		{fasthttp.StatusForbidden, errs.Unauthorized},
+		{fasthttp.StatusNotFound, errs.NotFound},
+		{fasthttp.StatusRequestTimeout, errs.Timeout},
+		{fasthttp.StatusGatewayTimeout, errs.Timeout},
+		{fasthttp.StatusTooManyRequests, errs.RateLimitExceeded},
+		{fasthttp.StatusBadGateway, errs.Unavailable},
+		{fasthttp.StatusServiceUnavailable, errs.Unavailable},
+		{fasthttp.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(fasthttp.StatusMessage(tt.statusCode), func(t *testing.T) {
+			err := errors.Wrap(&StatusError{StatusCode: tt.statusCode}, "wrapped")
+			if tt.expected == nil {
+				assert.False(t, errors.Is(err, errs.NotFound))
+				assert.False(t, errors.Is(err, errs.Unavailable))
+				return
+			}
+			assert.True(t, errors.Is(err, tt.expected))
+			assert.False(t, errors.Is(err, errs.InternalError))
+		})
+	}
+}
+
+func TestReturnStatusError(t *testing.T) {
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set("X-Request-Id", "abc")
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.WriteString("resource not found")
+	})
+
+	// disabled by default
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	resp, err := client.Get(context.Background(), "/foo", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusNotFound, resp.StatusCode())
+
+	client, err = New(baseURL, Config{ReturnStatusError: true})
+	require.NoError(t, err)
+
+	resp, err = client.Delete(context.Background(), "/foo", RequestOptions{})
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, errs.NotFound)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, fasthttp.StatusNotFound, statusErr.StatusCode)
+	assert.Equal(t, fasthttp.MethodDelete, statusErr.Method)
+	assert.Equal(t, baseURL+"/foo", statusErr.URL)
+	assert.Equal(t, "resource not found", string(statusErr.Body))
+	assert.Equal(t, "abc", statusErr.Header.Get("X-Request-Id"))
+}
@@ -1,14 +1,14 @@
 module github.com/Cleverse/go-utilities/httpclient
 
-go 1.23.0
-
-toolchain go1.23.5
+go 1.25
 
 require (
-	github.com/Cleverse/go-utilities/logger v0.0.0-20250514103453-c4b8e5219820
+	github.com/Cleverse/go-utilities/errs v0.0.0-20250808171844-1347aec4138e
+	github.com/Cleverse/go-utilities/logger v0.0.0-20250808171844-1347aec4138e
 	github.com/cockroachdb/errors v1.12.0
 	github.com/stretchr/testify v1.10.0
 	github.com/valyala/fasthttp v1.62.0
+	github.com/vmihailenco/msgpack v4.0.4+incompatible
 )
 
 require (
@@ -19,15 +19,19 @@ require (
 	github.com/getsentry/sentry-go v0.27.0 // indirect
 	github.com/goccy/go-json v0.10.5 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
+	github.com/lmittmann/tint v1.1.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/rogpeppe/go-internal v1.9.0 // indirect
+	github.com/rogpeppe/go-internal v1.13.1 // indirect
 	github.com/samber/lo v1.50.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	golang.org/x/sys v0.33.0 // indirect
-	golang.org/x/text v0.25.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
+	google.golang.org/appengine v1.6.8 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,141 @@
+package httpclient
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const defaultHedgeMaxAttempts = 2
+
+// HedgePolicy sends hedged requests: if a request is slower than Delay, another identical request is sent
+// without cancelling the first one, and the first successful response is used, the others are cancelled.
+// Transport errors, 5xx responses and responses with a status code of RetryPolicy.RetryStatusCodes are failed attempts,
+// the other requests are still awaited, and the last result is used if all requests fail.
+// It reduces tail latency at the cost of extra load on the server.
+//
+// Only idempotent methods are hedged. Requests with a body stream and streamed responses are never hedged.
+type HedgePolicy struct {
+	// Latency threshold after which the next hedged request is sent. Hedging is disabled if Delay <= 0.
+	Delay time.Duration
+
+	// Maximum number of requests including the first one. Default is 2.
+	MaxAttempts int
+}
+
+func (p HedgePolicy) withDefaults() HedgePolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultHedgeMaxAttempts
+	}
+	return p
+}
+
+// canHedge returns true if the request is allowed to be hedged.
+func (p HedgePolicy) canHedge(req *fasthttp.Request, resp *fasthttp.Response) bool {
+	if p.Delay <= 0 || p.MaxAttempts <= 1 {
+		return false
+	}
+	return isIdempotentMethod(string(req.Header.Method())) && !req.IsBodyStream() && !resp.StreamBody
+}
+
+type hedgeResult struct {
+	req  *fasthttp.Request
+	resp *fasthttp.Response
+	err  error
+}
+
+func (r hedgeResult) release() {
+	fasthttp.ReleaseRequest(r.req)
+	fasthttp.ReleaseResponse(r.resp)
+}
+
+// failed returns true if the response of a hedged request is a failed attempt.
+func (r hedgeResult) failed(retryStatusCodes []int) bool {
+	if r.err != nil {
+		return true
+	}
+	status := r.resp.StatusCode()
+	return status >= fasthttp.StatusInternalServerError || slices.Contains(retryStatusCodes, status)
+}
+
+// middleware returns the hedging middleware, the responses with a status code of retryStatusCodes are failed attempts.
+func (p HedgePolicy) middleware(retryStatusCodes []int) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			if !p.canHedge(req, resp) {
+				return next(ctx, req, resp)
+			}
+			state := requestStateFromContext(ctx)
+
+			// every hedged request has its own copy of req/resp, because the losers are still running when this function returns.
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			results := make(chan hedgeResult, p.MaxAttempts)
+			sent, pending := 0, 0
+			send := func() {
+				result := hedgeResult{
+					req:  fasthttp.AcquireRequest(),
+					resp: fasthttp.AcquireResponse(),
+				}
+				req.CopyTo(result.req)
+				sent++
+				pending++
+				go func() {
+					result.err = next(ctx, result.req, result.resp)
+					results <- result
+				}()
+			}
+
+			send()
+			timer := time.NewTimer(p.Delay)
+			defer timer.Stop()
+
+			// the last failed result, returned if all requests fail.
+			var last *hedgeResult
+			for {
+				select {
+				case <-timer.C:
+					if sent < p.MaxAttempts {
+						send()
+						state.hedges++
+						timer.Reset(p.Delay)
+					}
+				case result := <-results:
+					pending--
+					if !result.failed(retryStatusCodes) {
+						result.resp.CopyTo(resp)
+						result.release()
+
+						// cancel and release the losers in the background.
+						cancel()
+						go func(pending int) {
+							for range pending {
+								(<-results).release()
+							}
+						}(pending)
+						return nil
+					}
+					// a failed response is preferred to a transport error.
+					if last == nil || last.err != nil || result.err == nil {
+						if last != nil {
+							last.release()
+						}
+						last = &result
+					} else {
+						result.release()
+					}
+					if pending == 0 {
+						defer last.release()
+						if last.err != nil {
+							return last.err
+						}
+						last.resp.CopyTo(resp)
+						return nil
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// newSlowTestServer returns a server that sleeps for the delay of the request number (1-based) before replying the number.
+func newSlowTestServer(t *testing.T, delays ...time.Duration) (string, *atomic.Int32) {
+	t.Helper()
+
+	var count atomic.Int32
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		n := int(count.Add(1))
+		if n <= len(delays) {
+			time.Sleep(delays[n-1])
+		}
+		ctx.Response.Header.Set("X-Deadline", string(ctx.Request.Header.Peek("X-Deadline")))
+		ctx.WriteString(strconv.Itoa(n))
+	})
+
+	return baseURL, &count
+}
+
+func TestRequestTimeout(t *testing.T) {
+	baseURL, _ := newSlowTestServer(t, 200*time.Millisecond, 200*time.Millisecond)
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/", RequestOptions{Timeout: 50 * time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.DoStream(context.Background(), fasthttp.MethodGet, "/", RequestOptions{Timeout: 50 * time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	resp, err := client.Get(context.Background(), "/", RequestOptions{Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(resp.Body()))
+}
+
+func TestDeadlineHeader(t *testing.T) {
+	baseURL, _ := newSlowTestServer(t)
+	client, err := New(baseURL, Config{DeadlineHeader: "X-Deadline"})
+	require.NoError(t, err)
+
+	resp, err := client.Get(context.Background(), "/", RequestOptions{Timeout: time.Second})
+	require.NoError(t, err)
+	deadline, err := strconv.Atoi(string(resp.Header.Peek("X-Deadline")))
+	require.NoError(t, err)
+	assert.Greater(t, deadline, 900)
+	assert.LessOrEqual(t, deadline, 1000)
+
+	resp, err = client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Header.Peek("X-Deadline"))
+}
+
+func TestHedgeDeadlineHeader(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		deadlines []int
+	)
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		deadline, _ := strconv.Atoi(string(ctx.Request.Header.Peek("X-Deadline")))
+		mu.Lock()
+		deadlines = append(deadlines, deadline)
+		first := len(deadlines) == 1
+		mu.Unlock()
+		if first {
+			time.Sleep(time.Second)
+		}
+	})
+	client, err := New(baseURL, Config{
+		DeadlineHeader: "X-Deadline",
+		Hedge:          HedgePolicy{Delay: 100 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/", RequestOptions{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, deadlines, 2)
+	// the hedged request is sent later, so it has less time remaining.
+	assert.LessOrEqual(t, deadlines[1], deadlines[0]-90)
+}
+
+func TestHedge(t *testing.T) {
+	baseURL, count := newSlowTestServer(t, time.Second)
+	client, err := New(baseURL, Config{Hedge: HedgePolicy{Delay: 20 * time.Millisecond}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(resp.Body()))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, int32(2), count.Load())
+}
+
+func TestHedgeFailedResponse(t *testing.T) {
+	// the first request is slow, the hedged request fails fast with 503.
+	newServer := func(t *testing.T, firstStatus int) string {
+		var count atomic.Int32
+		return newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+			n := count.Add(1)
+			if n == 1 {
+				time.Sleep(200 * time.Millisecond)
+				ctx.SetStatusCode(firstStatus)
+			} else {
+				ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			}
+			ctx.WriteString(strconv.Itoa(int(n)))
+		})
+	}
+
+	t.Run("pending request succeeds", func(t *testing.T) {
+		client, err := New(newServer(t, fasthttp.StatusOK), Config{Hedge: HedgePolicy{Delay: 20 * time.Millisecond}})
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/", RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+		assert.Equal(t, "1", string(resp.Body()))
+	})
+
+	t.Run("all requests fail", func(t *testing.T) {
+		client, err := New(newServer(t, fasthttp.StatusInternalServerError), Config{Hedge: HedgePolicy{Delay: 20 * time.Millisecond}})
+		require.NoError(t, err)
+
+		resp, err := client.Get(context.Background(), "/", RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, fasthttp.StatusInternalServerError, resp.StatusCode())
+		assert.Equal(t, "1", string(resp.Body()))
+	})
+}
+
+func TestHedgeFastResponse(t *testing.T) {
+	baseURL, count := newSlowTestServer(t)
+	client, err := New(baseURL, Config{Hedge: HedgePolicy{Delay: 100 * time.Millisecond, MaxAttempts: 3}})
+	require.NoError(t, err)
+
+	resp, err := client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(resp.Body()))
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int32(1), count.Load())
+}
+
+func TestHedgeNonIdempotent(t *testing.T) {
+	baseURL, count := newSlowTestServer(t, 100*time.Millisecond)
+	client, err := New(baseURL, Config{Hedge: HedgePolicy{Delay: 10 * time.Millisecond}})
+	require.NoError(t, err)
+
+	resp, err := client.Post(context.Background(), "/", RequestOptions{Body: []byte("{}")})
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(resp.Body()))
+	assert.Equal(t, int32(1), count.Load())
+}
+
+func TestHedgeDebugLog(t *testing.T) {
+	baseURL, _ := newSlowTestServer(t, time.Second)
+
+	var buf bytes.Buffer
+	defaultLogger := logger.GetLogger()
+	logger.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer logger.SetLogger(defaultLogger)
+
+	client, err := New(baseURL, Config{
+		Debug:          true,
+		DeadlineHeader: "X-Deadline",
+		Hedge:          HedgePolicy{Delay: 20 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/", RequestOptions{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	log := buf.String()
+	assert.Contains(t, log, "hedges=1")
+	assert.Contains(t, log, "timeout=5s")
+	assert.Contains(t, log, "propagated_deadline=")
+}
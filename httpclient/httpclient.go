@@ -2,14 +2,12 @@ package httpclient
 
 import (
 	"context"
-	"encoding/json"
-	"log/slog"
+	"io"
 	"net/url"
 	"path"
 	"strings"
 	"time"
 
-	"github.com/Cleverse/go-utilities/logger"
 	"github.com/cockroachdb/errors"
 	"github.com/valyala/fasthttp"
 )
@@ -26,11 +24,48 @@ type Config struct {
 
 	// Default headers
 	Headers map[string]string
+
+	// Retry policy of failed requests, disabled by default.
+	Retry RetryPolicy
+
+	// Return *StatusError instead of *HttpResponse when the response status code is not successful (2xx).
+	ReturnStatusError bool
+
+	// Client-side rate limiting and concurrency limiting, disabled by default.
+	// The limiter is created when the client is created, changes after that are not applied.
+	RateLimit RateLimitConfig
+
+	// Per-host circuit breaker, disabled by default.
+	// The circuit breakers are created when the client is created, changes after that are not applied.
+	CircuitBreaker CircuitBreakerConfig
+
+	// Hedged requests of idempotent methods, disabled by default.
+	Hedge HedgePolicy
+
+	// Header to propagate the remaining time until the context deadline to the server in milliseconds
+	// (e.g. "X-Request-Timeout-Ms"), it's set for each attempt. Disabled if empty.
+	DeadlineHeader string
+
+	// Cache of GET responses, disabled by default.
+	// The cache is created when the client is created, changes after that are not applied.
+	Cache CacheConfig
+
+	// Tracer starts a span for each attempt of the requests, disabled if nil.
+	// The span context is sent in the W3C traceparent header, see ContextWithSpanContext.
+	Tracer Tracer
+
+	// Metrics records the in-flight requests, latency and errors of the requests, disabled if nil.
+	// See MetricsCollector for a Prometheus-compatible implementation.
+	Metrics Metrics
 }
 
 type Client struct {
-	baseURL        *url.URL
-	fasthttpClient *fasthttp.Client
+	baseURL     *url.URL
+	transport   Transport
+	middlewares []Middleware
+	rateLimiter *rateLimiter
+	breakers    *circuitBreakers
+	cache       *responseCache
 	Config
 }
 
@@ -42,7 +77,13 @@ func New(baseURL string, config ...Config) (*Client, error) {
 	return client, nil
 }
 
+// NewFromClient creates a client that sends requests with the fasthttp client.
 func NewFromClient(client *fasthttp.Client, baseURL string, config ...Config) (*Client, error) {
+	return NewFromTransport(NewFasthttpTransport(client), baseURL, config...)
+}
+
+// NewFromTransport creates a client that sends requests with the transport.
+func NewFromTransport(transport Transport, baseURL string, config ...Config) (*Client, error) {
 	var parsedBaseURL *url.URL
 	var err error
 	if baseURL == "" {
@@ -60,10 +101,15 @@ func NewFromClient(client *fasthttp.Client, baseURL string, config ...Config) (*
 	if len(cf.Headers) == 0 {
 		cf.Headers = make(map[string]string)
 	}
+	cf.Retry = cf.Retry.withDefaults()
+	cf.Hedge = cf.Hedge.withDefaults()
 	return &Client{
-		baseURL:        parsedBaseURL,
-		Config:         cf,
-		fasthttpClient: client,
+		baseURL:     parsedBaseURL,
+		Config:      cf,
+		transport:   transport,
+		rateLimiter: newRateLimiter(cf.RateLimit),
+		breakers:    newCircuitBreakers(cf.CircuitBreaker),
+		cache:       newResponseCache(cf.Cache),
 	}, nil
 }
 
@@ -74,6 +120,31 @@ type RequestOptions struct {
 	Query    url.Values
 	Header   map[string]string
 	FormData url.Values
+
+	// Timeout of the request including retries, in addition to the deadline of the context. No timeout if <= 0.
+	Timeout time.Duration
+
+	// Payload is encoded with the codec of ContentType and sent as the request body, it's ignored if Body is set.
+	Payload any
+
+	// ContentType of Body, Payload and BodyStream, default is application/json for Body and Payload.
+	ContentType string
+
+	// BodyStream is streamed as the request body instead of loading it into memory, it's ignored if Body or Payload is set.
+	// The Content-Type is application/octet-stream unless it's set by ContentType or the headers.
+	// If the reader implements io.Closer, it's closed when the request is finished.
+	// Requests with a body stream are never retried, because the body can't be read again.
+	BodyStream io.Reader
+
+	// BodyStreamSize is the size of BodyStream in bytes, sent as Content-Length.
+	// If it's not positive, the body is sent with chunked transfer encoding.
+	BodyStreamSize int
+
+	// Multipart is sent as a multipart/form-data body, it's ignored if Body, Payload or BodyStream is set.
+	Multipart *Multipart
+
+	// Route is the URL template of the request for spans and metrics, e.g. "/users/{id}". Default is the request path.
+	Route string
 }
 
 type HttpResponse struct {
@@ -81,36 +152,67 @@ type HttpResponse struct {
 	fasthttp.Response
 }
 
+// UnmarshalBody decodes the body into out with the codec registered for the response content type, see RegisterCodec.
 func (r *HttpResponse) UnmarshalBody(out any) error {
 	body, err := r.BodyUncompressed()
 	if err != nil {
 		return errors.Wrapf(err, "can't uncompress body from %v", r.URL)
 	}
-	contentType := strings.ToLower(string(r.Header.ContentType()))
-	switch {
-	case strings.Contains(contentType, "application/json"):
-		if err := json.Unmarshal(body, out); err != nil {
-			return errors.Wrapf(err, "can't unmarshal json body from %s, %q", r.URL, string(body))
-		}
-		return nil
-	case strings.Contains(contentType, "text/plain"):
-		return errors.Errorf("can't unmarshal plain text %q", string(body))
-	default:
-		return errors.Errorf("unsupported content type: %s, contents: %v", r.Header.ContentType(), string(r.Body()))
+	contentType := string(r.Header.ContentType())
+	codec, ok := LookupCodec(contentType)
+	if !ok {
+		return errors.Errorf("unsupported content type: %s, contents: %v", contentType, string(body))
 	}
+	if err := codec.Unmarshal(body, out); err != nil {
+		return errors.Wrapf(err, "can't unmarshal %s body from %s, %q", mediaType(contentType), r.URL, string(body))
+	}
+	return nil
 }
 
 func (h *Client) request(ctx context.Context, reqOptions RequestOptions) (*HttpResponse, error) {
 	start := time.Now()
-	req := fasthttp.AcquireRequest()
-	req.Header.SetMethod(reqOptions.method)
-	for k, v := range h.Headers {
-		req.Header.Set(k, v)
+	req, requestUrl, err := h.newRequest(reqOptions)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
-	for k, v := range reqOptions.Header {
-		req.Header.Set(k, v)
+	resp := fasthttp.AcquireResponse()
+	defer func() {
+		fasthttp.ReleaseResponse(resp)
+		fasthttp.ReleaseRequest(req)
+	}()
+
+	ctx, cancel := withTimeout(ctx, reqOptions.Timeout)
+	defer cancel()
+
+	ctx, state := withRequestState(ctx, start)
+	state.timeout = reqOptions.Timeout
+	state.route = reqOptions.Route
+	if err := h.roundTrip()(ctx, req, resp); err != nil {
+		return nil, errors.Wrapf(err, "error during request: url: %s, attempts: %d", requestUrl, state.attempts)
 	}
 
+	httpResponse := HttpResponse{
+		URL: requestUrl,
+	}
+	resp.CopyTo(&httpResponse.Response)
+
+	if h.ReturnStatusError && !isSuccessStatus(httpResponse.StatusCode()) {
+		return nil, errors.WithStack(newStatusError(reqOptions.method, &httpResponse))
+	}
+
+	return &httpResponse, nil
+}
+
+// withTimeout returns the context with the timeout, or the context itself if timeout <= 0.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// newRequest builds the fasthttp request from the request options. The caller must release the request.
+func (h *Client) newRequest(reqOptions RequestOptions) (*fasthttp.Request, string, error) {
 	baseUrl := h.BaseURL()
 	baseUrl.Path = path.Join(baseUrl.Path, reqOptions.path)
 	// Because path.Join cleans the joined path. If path ends with /, append "/" to parsedUrl.Path
@@ -129,70 +231,70 @@ func (h *Client) request(ctx context.Context, reqOptions RequestOptions) (*HttpR
 
 	// validate requestUrl
 	if _, err := url.Parse(requestUrl); err != nil {
-		return nil, errors.Wrapf(err, "can't parse request url: %s", requestUrl)
+		return nil, "", errors.Wrapf(err, "can't parse request url: %s", requestUrl)
 	}
 
-	req.SetRequestURI(requestUrl)
-	if reqOptions.Body != nil {
-		req.Header.SetContentType("application/json")
-		req.SetBody(reqOptions.Body)
-	} else if reqOptions.FormData != nil {
-		req.Header.SetContentType("application/x-www-form-urlencoded")
-		req.SetBodyString(reqOptions.FormData.Encode())
+	contentType := reqOptions.ContentType
+	if contentType == "" {
+		contentType = ContentTypeJSON
 	}
 
-	resp := fasthttp.AcquireResponse()
-	startDo := time.Now()
-
-	defer func() {
-		if h.Debug {
-			ctx := logger.WithContext(ctx,
-				slog.String("method", reqOptions.method),
-				slog.String("url", requestUrl),
-				slog.Duration("duration", time.Since(start)),
-				slog.Duration("latency", time.Since(startDo)),
-				slog.Int("req_header_size", len(req.Header.Header())),
-				slog.Int("req_content_length", req.Header.ContentLength()),
-			)
-
-			if resp.StatusCode() >= 0 {
-				ctx = logger.WithContext(ctx,
-					slog.Int("status_code", resp.StatusCode()),
-					slog.String("resp_content_type", string(resp.Header.ContentType())),
-					slog.String("resp_content_encoding", string(resp.Header.ContentEncoding())),
-					slog.Int("resp_content_length", len(resp.Body())),
-				)
-			}
-
-			logger.InfoContext(ctx, "Finished make request", slog.String("package", "httpclient"))
+	var body []byte
+	switch {
+	case reqOptions.Body != nil:
+		body = reqOptions.Body
+	case reqOptions.Payload != nil:
+		var err error
+		body, err = marshalBody(contentType, reqOptions.Payload)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "can't encode body of request url: %s", requestUrl)
 		}
+	case reqOptions.BodyStream == nil && reqOptions.Multipart != nil:
+		var err error
+		body, contentType, err = reqOptions.Multipart.encode()
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "can't encode multipart body of request url: %s", requestUrl)
+		}
+	}
 
-		fasthttp.ReleaseResponse(resp)
-		fasthttp.ReleaseRequest(req)
-	}()
-
-	resultCh := make(chan error, 1)
-
-	go func() {
-		resultCh <- errors.WithStack(h.fasthttpClient.Do(req, resp))
-	}()
-
-	var err error
-	select {
-	case <-ctx.Done():
-		err = ctx.Err()
-	case err = <-resultCh:
+	req := fasthttp.AcquireRequest()
+	req.Header.SetMethod(reqOptions.method)
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
 	}
-	if err != nil {
-		return nil, errors.Wrapf(err, "error during request: url: %s", requestUrl)
+	for k, v := range reqOptions.Header {
+		req.Header.Set(k, v)
 	}
 
-	httpResponse := HttpResponse{
-		URL: requestUrl,
+	req.SetRequestURI(requestUrl)
+	switch {
+	case body != nil:
+		req.Header.SetContentType(contentType)
+		req.SetBody(body)
+	case reqOptions.BodyStream != nil:
+		if reqOptions.ContentType != "" {
+			req.Header.SetContentType(reqOptions.ContentType)
+		} else if len(req.Header.ContentType()) == 0 {
+			req.Header.SetContentType("application/octet-stream")
+		}
+		bodySize := -1
+		if reqOptions.BodyStreamSize > 0 {
+			bodySize = reqOptions.BodyStreamSize
+		}
+		req.SetBodyStream(reqOptions.BodyStream, bodySize)
+	case reqOptions.FormData != nil:
+		req.Header.SetContentType("application/x-www-form-urlencoded")
+		req.SetBodyString(reqOptions.FormData.Encode())
 	}
-	resp.CopyTo(&httpResponse.Response)
+	return req, requestUrl, nil
+}
 
-	return &httpResponse, nil
+// CircuitState returns the circuit breaker state of the host. returns CircuitClosed if circuit breaker is disabled.
+func (h *Client) CircuitState(host string) CircuitState {
+	if h.breakers == nil {
+		return CircuitClosed
+	}
+	return h.breakers.host(host).currentState()
 }
 
 // BaseURL returns the cloned base URL of the client.
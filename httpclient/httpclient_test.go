@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
@@ -309,3 +313,93 @@ func TestContextTimeout(t *testing.T) {
 	_, err = client.Get(ctx, "/", RequestOptions{})
 	assert.ErrorIs(t, err, context.DeadlineExceeded)
 }
+
+func TestContextCancelStress(t *testing.T) {
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		time.Sleep(time.Duration(len(ctx.PostBody())%5) * time.Millisecond)
+		ctx.SetContentType("application/json")
+		ctx.Write(ctx.PostBody())
+	})
+
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var (
+				ctx    context.Context
+				cancel context.CancelFunc
+			)
+			if i%2 == 0 {
+				ctx, cancel = context.WithTimeout(context.Background(), time.Duration(i%4)*time.Millisecond)
+			} else {
+				ctx, cancel = context.WithCancel(context.Background())
+			}
+			go func() {
+				time.Sleep(time.Duration(i%3) * time.Millisecond)
+				cancel()
+			}()
+			defer cancel()
+
+			body := []byte(`{"message":"` + strings.Repeat("x", i%7) + `"}`)
+			resp, err := client.Post(ctx, "/", RequestOptions{Body: body})
+			if err != nil {
+				assert.True(t, errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+				return
+			}
+			assert.Equal(t, body, resp.Body())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestContextCancelHungServer(t *testing.T) {
+	// the server accepts the connections but never answers.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	var (
+		mu    sync.Mutex
+		conns []net.Conn
+	)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	transport := &fasthttpTransport{client: &fasthttp.Client{}, backgroundTimeout: 200 * time.Millisecond}
+	client, err := NewFromTransport(transport, "http://"+ln.Addr().String())
+	require.NoError(t, err)
+
+	baseline := runtime.NumGoroutine()
+	for range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, err := client.Get(ctx, "/", RequestOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+
+	// the cancelled requests are finished by the background timeout, a few background goroutines of fasthttp
+	// (e.g. the connection cleaner) may remain, instead of two goroutines per request.
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= baseline+5
+	}, 2*time.Second, 50*time.Millisecond)
+}
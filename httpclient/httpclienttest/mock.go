@@ -0,0 +1,339 @@
+/*
+httpclienttest provides test doubles for the httpclient package.
+
+Mock is an in-process transport and server with expectations by method, path and query,
+and Recorder records real interactions to golden files and replays them in tests.
+*/
+package httpclienttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/httpclient"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// ErrUnexpectedRequest is returned by Mock when the request doesn't match any expectation.
+var ErrUnexpectedRequest = errors.New("httpclienttest: unexpected request")
+
+// Mock is an in-process transport with expectations, it replies canned responses without network.
+// Unmatched requests and unmet expectations fail the test at the end of the test.
+//
+//	mock := httpclienttest.NewMock(t)
+//	mock.On(fasthttp.MethodGet, "/users/1").ReplyJSON(fasthttp.StatusOK, User{ID: 1}).Times(1)
+//
+//	client := mock.Client("http://api.example.com")
+//	// or for httpclient.Get and other clients, send requests to mock.URL().
+type Mock struct {
+	t testing.TB
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+
+	serverOnce sync.Once
+	url        string
+}
+
+// NewMock creates a mock that asserts the expectations when the test is finished.
+func NewMock(t testing.TB) *Mock {
+	t.Helper()
+
+	m := &Mock{t: t}
+	t.Cleanup(func() {
+		m.AssertExpectations(t)
+	})
+	return m
+}
+
+// On registers an expectation of requests with the method and path. Expectations are matched in the order they are registered,
+// an expectation that reached its Times limit is skipped.
+func (m *Mock) On(method, path string) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &Expectation{
+		method:     method,
+		path:       path,
+		query:      make(url.Values),
+		statusCode: fasthttp.StatusOK,
+		header:     make(map[string]string),
+	}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// Client creates a client that sends requests to the mock transport.
+func (m *Mock) Client(baseURL string, config ...httpclient.Config) *httpclient.Client {
+	m.t.Helper()
+
+	client, err := httpclient.NewFromTransport(m, baseURL, config...)
+	if err != nil {
+		m.t.Fatalf("httpclienttest: can't create client: %v", err)
+	}
+	return client
+}
+
+// URL starts an in-process server on the loopback interface that replies from the mock, and returns its base URL.
+// It's for code that doesn't accept a client, e.g. httpclient.Get. The server is closed when the test is finished.
+func (m *Mock) URL() string {
+	m.t.Helper()
+
+	m.serverOnce.Do(func() {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			m.t.Fatalf("httpclienttest: can't listen: %v", err)
+		}
+		server := &fasthttp.Server{
+			Handler: func(ctx *fasthttp.RequestCtx) {
+				if err := m.Do(ctx, &ctx.Request, &ctx.Response); err != nil {
+					ctx.Error(err.Error(), fasthttp.StatusNotImplemented)
+				}
+			},
+		}
+		go server.Serve(ln) // nolint: errcheck
+		m.t.Cleanup(func() {
+			_ = server.Shutdown()
+		})
+		m.url = "http://" + ln.Addr().String()
+	})
+	return m.url
+}
+
+// Do replies the response of the first matched expectation, it implements httpclient.Transport.
+func (m *Mock) Do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	e := m.match(req)
+	if e == nil {
+		request := fmt.Sprintf("%s %s", req.Header.Method(), req.URI().RequestURI())
+		m.mu.Lock()
+		m.unexpected = append(m.unexpected, request)
+		m.mu.Unlock()
+		return errors.Wrap(ErrUnexpectedRequest, request)
+	}
+	return e.reply(ctx, req, resp)
+}
+
+func (m *Mock) match(req *fasthttp.Request) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		if e.matches(req) && e.reserve() {
+			return e
+		}
+	}
+	return nil
+}
+
+// Calls returns the number of requests matched by the expectations of the method and path.
+func (m *Mock) Calls(method, path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls int
+	for _, e := range m.expectations {
+		if e.method == method && e.path == path {
+			calls += e.Calls()
+		}
+	}
+	return calls
+}
+
+// AssertExpectations fails the test if there were unexpected requests, or an expectation wasn't called the expected number of times.
+// It's called automatically when the test is finished.
+func (m *Mock) AssertExpectations(t testing.TB) bool {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := true
+	for _, request := range m.unexpected {
+		t.Errorf("httpclienttest: unexpected request: %s", request)
+		ok = false
+	}
+	for _, e := range m.expectations {
+		calls := e.Calls()
+		switch {
+		case e.times > 0 && calls != e.times:
+			t.Errorf("httpclienttest: expected %s to be called %d times, but it was called %d times", e, e.times, calls)
+			ok = false
+		case e.times == 0 && !e.optional && calls == 0:
+			t.Errorf("httpclienttest: expected %s to be called, but it wasn't called", e)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Expectation is a request expectation of Mock and its canned response.
+// The default response is 200 OK with an empty body.
+type Expectation struct {
+	method   string
+	path     string
+	query    url.Values
+	times    int
+	optional bool
+
+	statusCode int
+	header     map[string]string
+	body       []byte
+	delay      time.Duration
+	err        error
+	fn         func(req *fasthttp.Request, resp *fasthttp.Response) error
+
+	mu    sync.Mutex
+	calls int
+}
+
+// WithQuery matches only requests with the query parameter. All values must be present in the request.
+func (e *Expectation) WithQuery(key string, values ...string) *Expectation {
+	e.query[key] = append(e.query[key], values...)
+	return e
+}
+
+// Times sets the exact number of expected calls, the expectation doesn't match more requests after that.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Once is a shortcut for Times(1).
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Maybe marks the expectation as optional, it's not an error if it's never called.
+func (e *Expectation) Maybe() *Expectation {
+	e.optional = true
+	return e
+}
+
+// Reply sets the status code and body of the response.
+func (e *Expectation) Reply(statusCode int, body []byte) *Expectation {
+	e.statusCode = statusCode
+	e.body = body
+	return e
+}
+
+// ReplyString sets the status code and plain text body of the response.
+func (e *Expectation) ReplyString(statusCode int, body string) *Expectation {
+	e.header[fasthttp.HeaderContentType] = httpclient.ContentTypeText
+	return e.Reply(statusCode, []byte(body))
+}
+
+// ReplyJSON sets the status code and JSON body of the response. It panics if v can't be marshaled.
+func (e *Expectation) ReplyJSON(statusCode int, v any) *Expectation {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httpclienttest: can't marshal reply of %s: %v", e, err))
+	}
+	e.header[fasthttp.HeaderContentType] = httpclient.ContentTypeJSON
+	return e.Reply(statusCode, body)
+}
+
+// ReplyHeader sets a header of the response.
+func (e *Expectation) ReplyHeader(key, value string) *Expectation {
+	e.header[key] = value
+	return e
+}
+
+// ReplyError makes the transport fail with err instead of replying a response.
+func (e *Expectation) ReplyError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// ReplyFunc replies the response with fn, it overrides the other replies.
+func (e *Expectation) ReplyFunc(fn func(req *fasthttp.Request, resp *fasthttp.Response) error) *Expectation {
+	e.fn = fn
+	return e
+}
+
+// Delay delays the response, or until the context of the request is done.
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+// Calls returns the number of requests matched by the expectation.
+func (e *Expectation) Calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
+
+func (e *Expectation) String() string {
+	if len(e.query) == 0 {
+		return fmt.Sprintf("%s %s", e.method, e.path)
+	}
+	return fmt.Sprintf("%s %s?%s", e.method, e.path, e.query.Encode())
+}
+
+func (e *Expectation) matches(req *fasthttp.Request) bool {
+	if string(req.Header.Method()) != e.method || string(req.URI().Path()) != e.path {
+		return false
+	}
+	args := req.URI().QueryArgs()
+	for key, values := range e.query {
+		for _, value := range values {
+			if !args.Has(key) || !containsValue(args.PeekMulti(key), value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// reserve counts the call if the expectation didn't reach its Times limit.
+func (e *Expectation) reserve() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.times > 0 && e.calls >= e.times {
+		return false
+	}
+	e.calls++
+	return true
+}
+
+func (e *Expectation) reply(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if e.delay > 0 {
+		timer := time.NewTimer(e.delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-timer.C:
+		}
+	}
+	if e.err != nil {
+		return e.err
+	}
+	if e.fn != nil {
+		return e.fn(req, resp)
+	}
+
+	resp.SetStatusCode(e.statusCode)
+	for key, value := range e.header {
+		resp.Header.Set(key, value)
+	}
+	resp.SetBody(e.body)
+	return nil
+}
+
+func containsValue(values [][]byte, value string) bool {
+	for _, v := range values {
+		if string(v) == value {
+			return true
+		}
+	}
+	return false
+}
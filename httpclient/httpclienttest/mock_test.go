@@ -0,0 +1,130 @@
+package httpclienttest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/httpclient"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+type testUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// fakeT records the failures of the mock, to test failed expectations without failing the test.
+type fakeT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeT) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestMock(t *testing.T) {
+	mock := NewMock(t)
+	mock.On(fasthttp.MethodGet, "/users/1").ReplyJSON(fasthttp.StatusOK, testUser{ID: 1, Name: "alice"}).Once()
+	mock.On(fasthttp.MethodGet, "/users").WithQuery("name", "bob").ReplyJSON(fasthttp.StatusOK, []testUser{{ID: 2, Name: "bob"}})
+	mock.On(fasthttp.MethodGet, "/users").ReplyString(fasthttp.StatusBadRequest, "name is required")
+	mock.On(fasthttp.MethodPost, "/users").ReplyFunc(func(req *fasthttp.Request, resp *fasthttp.Response) error {
+		resp.SetStatusCode(fasthttp.StatusCreated)
+		resp.SetBody(req.Body())
+		return nil
+	})
+
+	client := mock.Client("http://api.example.com")
+	ctx := context.Background()
+
+	user, err := httpclient.GetJSON[testUser](ctx, client, "/users/1", httpclient.RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, testUser{ID: 1, Name: "alice"}, user)
+
+	users, err := httpclient.GetJSON[[]testUser](ctx, client, "/users", httpclient.RequestOptions{
+		Query: map[string][]string{"name": {"bob"}, "page": {"1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []testUser{{ID: 2, Name: "bob"}}, users)
+
+	resp, err := client.Get(ctx, "/users", httpclient.RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusBadRequest, resp.StatusCode())
+	assert.Equal(t, "name is required", string(resp.Body()))
+
+	resp, err = client.Post(ctx, "/users", httpclient.RequestOptions{Body: []byte(`{"name":"carol"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusCreated, resp.StatusCode())
+	assert.Equal(t, `{"name":"carol"}`, string(resp.Body()))
+
+	assert.Equal(t, 1, mock.Calls(fasthttp.MethodGet, "/users/1"))
+	assert.Equal(t, 2, mock.Calls(fasthttp.MethodGet, "/users"))
+}
+
+func TestMockURL(t *testing.T) {
+	mock := NewMock(t)
+	mock.On(fasthttp.MethodGet, "/health").ReplyString(fasthttp.StatusOK, "ok").Times(2)
+
+	for range 2 {
+		resp, err := httpclient.Get(context.Background(), mock.URL()+"/health", httpclient.RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(resp.Body()))
+	}
+	assert.Equal(t, 2, mock.Calls(fasthttp.MethodGet, "/health"))
+}
+
+func TestMockError(t *testing.T) {
+	mock := NewMock(t)
+	mock.On(fasthttp.MethodGet, "/down").ReplyError(fasthttp.ErrConnectionClosed)
+	mock.On(fasthttp.MethodGet, "/slow").Delay(time.Second).Maybe()
+
+	client := mock.Client("")
+
+	_, err := client.Get(context.Background(), "/down", httpclient.RequestOptions{})
+	assert.ErrorIs(t, err, fasthttp.ErrConnectionClosed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Get(ctx, "/slow", httpclient.RequestOptions{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMockAssertExpectations(t *testing.T) {
+	ft := &fakeT{TB: t}
+	mock := NewMock(ft)
+	mock.On(fasthttp.MethodGet, "/once").Once()
+	mock.On(fasthttp.MethodGet, "/never")
+	mock.On(fasthttp.MethodGet, "/optional").Maybe()
+
+	client := mock.Client("")
+	for range 2 {
+		client.Get(context.Background(), "/once", httpclient.RequestOptions{})
+	}
+	_, err := client.Delete(context.Background(), "/unknown", httpclient.RequestOptions{})
+	assert.True(t, errors.Is(err, ErrUnexpectedRequest))
+
+	ft.finish()
+	assert.Equal(t, []string{
+		"httpclienttest: unexpected request: GET /once",
+		"httpclienttest: unexpected request: DELETE /unknown",
+		"httpclienttest: expected GET /never to be called, but it wasn't called",
+	}, ft.errors)
+}
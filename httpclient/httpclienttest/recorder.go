@@ -0,0 +1,323 @@
+package httpclienttest
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Cleverse/go-utilities/httpclient"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// RecordEnv is the environment variable that switches ModeFromEnv to ModeRecord, e.g. HTTPCLIENTTEST_RECORD=1 go test ./...
+const RecordEnv = "HTTPCLIENTTEST_RECORD"
+
+// ErrNoInteraction is returned by Recorder in replay mode when the golden file has no more interactions for the request.
+var ErrNoInteraction = errors.New("httpclienttest: no recorded interaction")
+
+// skipped response headers, they change on every run or are set from the body.
+var recorderSkipHeaders = map[string]bool{
+	fasthttp.HeaderDate:             true,
+	fasthttp.HeaderContentLength:    true,
+	fasthttp.HeaderTransferEncoding: true,
+	fasthttp.HeaderConnection:       true,
+}
+
+// RedactedValue replaces the redacted values in golden files.
+const RedactedValue = "REDACTED"
+
+// query parameters redacted by DefaultRedact, matched case-insensitively.
+var defaultRedactedParams = []string{
+	"access_token", "api_key", "api-key", "apikey", "auth", "client_secret", "key",
+	"password", "secret", "sig", "signature", "token",
+}
+
+// response headers redacted by DefaultRedact.
+var defaultRedactedHeaders = []string{
+	fasthttp.HeaderSetCookie,
+}
+
+// DefaultRedact redacts the common credential query parameters (e.g. apikey, token, signature) of the request URL
+// and the Set-Cookie headers of the response. It's the default Recorder.Redact.
+func DefaultRedact(interaction *Interaction) {
+	interaction.Request.URL = RedactURL(interaction.Request.URL, defaultRedactedParams...)
+	for key, values := range interaction.Response.Header {
+		if !containsFold(defaultRedactedHeaders, key) {
+			continue
+		}
+		for i := range values {
+			values[i] = RedactedValue
+		}
+	}
+}
+
+// RedactURL replaces the values of the query parameters of the URL with RedactedValue,
+// the parameters are matched case-insensitively. The URL is returned as is if it has none of the parameters.
+func RedactURL(rawURL string, params ...string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	query := u.Query()
+	redacted := false
+	for key, values := range query {
+		if !containsFold(params, key) {
+			continue
+		}
+		for i := range values {
+			values[i] = RedactedValue
+		}
+		redacted = true
+	}
+	if !redacted {
+		return rawURL
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mode is the mode of Recorder.
+type Mode int
+
+const (
+	// ModeReplay replies the recorded interactions from the golden file without network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends the requests with the real transport and saves the interactions to the golden file.
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord if the RecordEnv environment variable is set, otherwise ModeReplay.
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Interaction is a recorded request and response of a golden file.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request. Headers are not recorded, but the URL and the body are,
+// see Recorder.Redact to remove credentials from golden files.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   Body   `json:"body,omitzero"`
+}
+
+// RecordedResponse is a recorded response.
+type RecordedResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       Body                `json:"body,omitzero"`
+}
+
+// Body is a recorded body, it's saved as a string if it's valid UTF-8, otherwise as base64.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		data, err := json.Marshal(string(b))
+		return data, errors.WithStack(err)
+	}
+	data, err := json.Marshal(map[string][]byte{"base64": b})
+	return data, errors.WithStack(err)
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	var encoded map[string][]byte
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return errors.WithStack(err)
+	}
+	*b = encoded["base64"]
+	return nil
+}
+
+// Recorder is a transport that records real interactions to a golden file, and replays them in later runs.
+//
+// In replay mode, the requests are matched with the recorded interactions by method and URL in the recorded order,
+// so repeated requests reply the successive responses. The request body is not matched.
+//
+// The interactions are passed to Redact before they're saved, so credentials in the URL query or the response
+// headers are not committed with the golden files. The requests are redacted the same way before they're matched.
+//
+//	recorder := httpclienttest.NewRecorder(t, "testdata/users.json", httpclienttest.ModeFromEnv(), nil)
+//	client, err := httpclient.NewFromTransport(recorder, "https://api.example.com")
+type Recorder struct {
+	// Redact removes credentials from the recorded interactions, DefaultRedact if not changed.
+	// It must be deterministic, and set before the first request. Set to nil to record the interactions as is.
+	Redact func(interaction *Interaction)
+
+	goldenFile string
+	mode       Mode
+	next       httpclient.Transport
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder creates a recorder of the golden file. In record mode, the requests are sent with next
+// (a fasthttp transport if nil), and the golden file is written when the test is finished.
+// In replay mode, the golden file is loaded and the test fails if it can't be read.
+func NewRecorder(t testing.TB, goldenFile string, mode Mode, next httpclient.Transport) *Recorder {
+	t.Helper()
+
+	if next == nil {
+		next = httpclient.NewFasthttpTransport(&fasthttp.Client{})
+	}
+	r := &Recorder{
+		Redact:     DefaultRedact,
+		goldenFile: goldenFile,
+		mode:       mode,
+		next:       next,
+	}
+
+	switch mode {
+	case ModeRecord:
+		t.Cleanup(func() {
+			if err := r.save(); err != nil {
+				t.Errorf("httpclienttest: can't save golden file: %+v", err)
+			}
+		})
+	case ModeReplay:
+		if err := r.load(); err != nil {
+			t.Fatalf("httpclienttest: can't load golden file: %+v", err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+	return r
+}
+
+// Interactions returns the recorded or loaded interactions.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Do records or replays the request, it implements httpclient.Transport.
+func (r *Recorder) Do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if r.mode == ModeRecord {
+		return r.record(ctx, req, resp)
+	}
+	return r.replay(req, resp)
+}
+
+func (r *Recorder) record(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	// the body stream is read once, buffer it to record it.
+	reqBody := append([]byte(nil), req.Body()...)
+	if err := r.next.Do(ctx, req, resp); err != nil {
+		return errors.WithStack(err)
+	}
+
+	header := make(map[string][]string)
+	resp.Header.VisitAll(func(key, value []byte) {
+		if !recorderSkipHeaders[string(key)] {
+			header[string(key)] = append(header[string(key)], string(value))
+		}
+	})
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: string(req.Header.Method()),
+			URL:    req.URI().String(),
+			Body:   reqBody,
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode(),
+			Header:     header,
+			// reads the body stream into memory, the response is replied from the buffered body.
+			Body: append([]byte(nil), resp.Body()...),
+		},
+	}
+	if r.Redact != nil {
+		r.Redact(&interaction)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, interaction)
+	return nil
+}
+
+func (r *Recorder) replay(req *fasthttp.Request, resp *fasthttp.Response) error {
+	method, url := string(req.Header.Method()), req.URI().String()
+	if r.Redact != nil {
+		// match the redacted URL of the recorded interactions.
+		interaction := Interaction{Request: RecordedRequest{Method: method, URL: url}}
+		r.Redact(&interaction)
+		url = interaction.Request.URL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Request.Method != method || interaction.Request.URL != url {
+			continue
+		}
+		r.used[i] = true
+
+		resp.SetStatusCode(interaction.Response.StatusCode)
+		for key, values := range interaction.Response.Header {
+			for _, value := range values {
+				resp.Header.Add(key, value)
+			}
+		}
+		resp.SetBody(interaction.Response.Body)
+		return nil
+	}
+	return errors.Wrapf(ErrNoInteraction, "%s %s", method, url)
+}
+
+func (r *Recorder) load() error {
+	data, err := os.ReadFile(r.goldenFile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := json.Unmarshal(data, &r.interactions); err != nil {
+		return errors.Wrapf(err, "can't unmarshal golden file %s", r.goldenFile)
+	}
+	return nil
+}
+
+func (r *Recorder) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.goldenFile), 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(r.goldenFile, append(data, '\n'), 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
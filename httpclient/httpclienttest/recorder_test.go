@@ -0,0 +1,147 @@
+package httpclienttest
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Cleverse/go-utilities/httpclient"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRecorder(t *testing.T) {
+	var count atomic.Int32
+	server := &fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			n := count.Add(1)
+			ctx.Response.Header.Set("X-Count", strconv.Itoa(int(n)))
+			switch string(ctx.Path()) {
+			case "/binary":
+				ctx.SetContentType("application/octet-stream")
+				ctx.Write([]byte{0xff, 0x00, 0xfe})
+			default:
+				ctx.SetContentType(httpclient.ContentTypeJSON)
+				ctx.Write(ctx.PostBody())
+			}
+		},
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(ln)
+	baseURL := "http://" + ln.Addr().String()
+
+	goldenFile := filepath.Join(t.TempDir(), "testdata", "recorder.json")
+	ctx := context.Background()
+
+	send := func(t *testing.T, client *httpclient.Client) {
+		t.Helper()
+
+		for i := 1; i <= 2; i++ {
+			resp, err := client.Post(ctx, "/echo", httpclient.RequestOptions{Body: []byte(`{"message":"hello"}`)})
+			require.NoError(t, err)
+			assert.Equal(t, `{"message":"hello"}`, string(resp.Body()))
+			assert.Equal(t, strconv.Itoa(i), string(resp.Header.Peek("X-Count")))
+			assert.Equal(t, httpclient.ContentTypeJSON, string(resp.Header.ContentType()))
+		}
+
+		resp, err := client.Get(ctx, "/binary", httpclient.RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0xff, 0x00, 0xfe}, resp.Body())
+	}
+
+	t.Run("record", func(t *testing.T) {
+		ft := &fakeT{TB: t}
+		recorder := NewRecorder(ft, goldenFile, ModeRecord, nil)
+		client, err := httpclient.NewFromTransport(recorder, baseURL)
+		require.NoError(t, err)
+
+		send(t, client)
+		ft.finish()
+		assert.Empty(t, ft.errors)
+		assert.Len(t, recorder.Interactions(), 3)
+	})
+
+	// replay without the server.
+	require.NoError(t, ln.Close())
+
+	t.Run("replay", func(t *testing.T) {
+		recorder := NewRecorder(t, goldenFile, ModeReplay, nil)
+		client, err := httpclient.NewFromTransport(recorder, baseURL)
+		require.NoError(t, err)
+
+		send(t, client)
+		assert.Equal(t, int32(3), count.Load())
+
+		_, err = client.Get(ctx, "/binary", httpclient.RequestOptions{})
+		assert.True(t, errors.Is(err, ErrNoInteraction))
+	})
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv(RecordEnv, "")
+	assert.Equal(t, ModeReplay, ModeFromEnv())
+
+	t.Setenv(RecordEnv, "1")
+	assert.Equal(t, ModeRecord, ModeFromEnv())
+}
+
+func TestRecorderRedact(t *testing.T) {
+	server := &fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			ctx.Response.Header.Set(fasthttp.HeaderSetCookie, "session=s3cr3t")
+			ctx.WriteString("ok")
+		},
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go server.Serve(ln)
+	baseURL := "http://" + ln.Addr().String()
+
+	goldenFile := filepath.Join(t.TempDir(), "redact.json")
+	ctx := context.Background()
+
+	ft := &fakeT{TB: t}
+	recorder := NewRecorder(ft, goldenFile, ModeRecord, nil)
+	client, err := httpclient.NewFromTransport(recorder, baseURL)
+	require.NoError(t, err)
+	_, err = client.Get(ctx, "/users", httpclient.RequestOptions{
+		Query: url.Values{"page": {"1"}, "apikey": {"s3cr3t"}, "Token": {"s3cr3t"}},
+	})
+	require.NoError(t, err)
+	ft.finish()
+	require.Empty(t, ft.errors)
+
+	data, err := os.ReadFile(goldenFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+	interactions := recorder.Interactions()
+	require.Len(t, interactions, 1)
+	assert.Equal(t, baseURL+"/users?Token=REDACTED&apikey=REDACTED&page=1", interactions[0].Request.URL)
+	assert.Equal(t, []string{RedactedValue}, interactions[0].Response.Header[fasthttp.HeaderSetCookie])
+
+	// replay matches the request with other credentials by the redacted URL.
+	recorder = NewRecorder(t, goldenFile, ModeReplay, nil)
+	client, err = httpclient.NewFromTransport(recorder, baseURL)
+	require.NoError(t, err)
+	resp, err := client.Get(ctx, "/users", httpclient.RequestOptions{
+		Query: url.Values{"page": {"1"}, "apikey": {"other"}, "Token": {"other"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(resp.Body()))
+}
+
+func TestRedactURL(t *testing.T) {
+	assert.Equal(t, "https://example.com/a?page=1", RedactURL("https://example.com/a?page=1", "apikey"))
+	assert.Equal(t, "https://example.com/a", RedactURL("https://example.com/a", "apikey"))
+	assert.Equal(t, "https://example.com/a?KEY=REDACTED&page=1", RedactURL("https://example.com/a?page=1&KEY=x", "key"))
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// DoJSON sends a request with the JSON encoded body (if body is not nil, including typed nil pointers, maps and slices) and decodes the JSON response body into Resp.
+// returns *StatusError if the response status code is not successful (2xx).
+func DoJSON[Resp any](ctx context.Context, c *Client, method, path string, body any, reqOptions RequestOptions) (result Resp, err error) {
+	if !isNilBody(body) {
+		reqOptions.Body, err = json.Marshal(body)
+		if err != nil {
+			return result, errors.Wrap(err, "can't marshal request body")
+		}
+	}
+	if !hasHeader(reqOptions.Header, fasthttp.HeaderAccept) {
+		header := make(map[string]string, len(reqOptions.Header)+1)
+		for k, v := range reqOptions.Header {
+			header[k] = v
+		}
+		header[fasthttp.HeaderAccept] = "application/json"
+		reqOptions.Header = header
+	}
+
+	resp, err := c.Do(ctx, method, path, reqOptions)
+	if err != nil {
+		return result, errors.WithStack(err)
+	}
+	if !isSuccessStatus(resp.StatusCode()) {
+		return result, errors.WithStack(newStatusError(method, resp))
+	}
+
+	respBody, err := resp.BodyUncompressed()
+	if err != nil {
+		return result, errors.Wrapf(err, "can't uncompress body from %v", resp.URL)
+	}
+	if len(respBody) == 0 {
+		return result, nil
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return result, errors.Wrapf(err, "can't unmarshal json body from %s, %q", resp.URL, string(respBody))
+	}
+	return result, nil
+}
+
+// GetJSON sends a GET request and decodes the JSON response body into Resp. see DoJSON.
+func GetJSON[Resp any](ctx context.Context, c *Client, path string, reqOptions RequestOptions) (Resp, error) {
+	return DoJSON[Resp](ctx, c, fasthttp.MethodGet, path, nil, reqOptions)
+}
+
+// PostJSON sends a POST request with the JSON encoded body and decodes the JSON response body into Resp. see DoJSON.
+func PostJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, reqOptions RequestOptions) (Resp, error) {
+	return DoJSON[Resp](ctx, c, fasthttp.MethodPost, path, body, reqOptions)
+}
+
+// PutJSON sends a PUT request with the JSON encoded body and decodes the JSON response body into Resp. see DoJSON.
+func PutJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, reqOptions RequestOptions) (Resp, error) {
+	return DoJSON[Resp](ctx, c, fasthttp.MethodPut, path, body, reqOptions)
+}
+
+// PatchJSON sends a PATCH request with the JSON encoded body and decodes the JSON response body into Resp. see DoJSON.
+func PatchJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, reqOptions RequestOptions) (Resp, error) {
+	return DoJSON[Resp](ctx, c, fasthttp.MethodPatch, path, body, reqOptions)
+}
+
+// DeleteJSON sends a DELETE request and decodes the JSON response body into Resp. see DoJSON.
+func DeleteJSON[Resp any](ctx context.Context, c *Client, path string, reqOptions RequestOptions) (Resp, error) {
+	return DoJSON[Resp](ctx, c, fasthttp.MethodDelete, path, nil, reqOptions)
+}
+
+// isNilBody returns true if body is nil or a nil pointer, map, slice or interface, e.g. a nil *Req of PostJSON.
+func isNilBody(body any) bool {
+	if body == nil {
+		return true
+	}
+	v := reflect.ValueOf(body)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// hasHeader returns true if the header has the name, header names are case-insensitive.
+func hasHeader(header map[string]string, name string) bool {
+	for k := range header {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+type testRequest struct {
+	Name string `json:"name"`
+}
+
+func newJSONTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		switch string(ctx.Path()) {
+		case "/echo":
+			var req testRequest
+			if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+				ctx.SetStatusCode(fasthttp.StatusBadRequest)
+				return
+			}
+			ctx.SetContentType("application/json")
+			json.NewEncoder(ctx).Encode(testResponse{Message: string(ctx.Method()) + " " + req.Name})
+		case "/accept":
+			ctx.SetContentType("application/json")
+			json.NewEncoder(ctx).Encode(testResponse{Message: string(ctx.Request.Header.Peek(fasthttp.HeaderAccept))})
+		case "/body":
+			ctx.SetContentType("application/json")
+			json.NewEncoder(ctx).Encode(testResponse{Message: string(ctx.PostBody())})
+		case "/no-content":
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+		default:
+			ctx.SetStatusCode(fasthttp.StatusNotFound)
+			ctx.WriteString(strings.Repeat("x", 2*maxErrorBodySize))
+		}
+	})
+
+	client, err := New(baseURL)
+	require.NoError(t, err)
+	return client
+}
+
+func TestJSONHelpers(t *testing.T) {
+	client := newJSONTestClient(t)
+	ctx := context.Background()
+
+	result, err := GetJSON[testResponse](ctx, client, "/accept", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", result.Message)
+
+	result, err = PostJSON[testRequest, testResponse](ctx, client, "/echo", testRequest{Name: "foo"}, RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "POST foo", result.Message)
+
+	result, err = PutJSON[testRequest, testResponse](ctx, client, "/echo", testRequest{Name: "bar"}, RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "PUT bar", result.Message)
+
+	result, err = PatchJSON[testRequest, testResponse](ctx, client, "/echo", testRequest{Name: "baz"}, RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH baz", result.Message)
+
+	result, err = DeleteJSON[testResponse](ctx, client, "/no-content", RequestOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Message)
+}
+
+func TestJSONHelpersNilBody(t *testing.T) {
+	client := newJSONTestClient(t)
+
+	result, err := PostJSON[*testRequest, testResponse](context.Background(), client, "/body", nil, RequestOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Message)
+
+	result, err = PostJSON[*testRequest, testResponse](context.Background(), client, "/body", &testRequest{Name: "foo"}, RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"foo"}`, result.Message)
+}
+
+func TestJSONHelpersAcceptHeader(t *testing.T) {
+	client := newJSONTestClient(t)
+
+	result, err := GetJSON[testResponse](context.Background(), client, "/accept", RequestOptions{
+		Header: map[string]string{"accept": "application/vnd.api+json"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "application/vnd.api+json", result.Message)
+}
+
+func TestJSONHelpersStatusError(t *testing.T) {
+	client := newJSONTestClient(t)
+
+	_, err := GetJSON[testResponse](context.Background(), client, "/not-found", RequestOptions{})
+	require.Error(t, err)
+
+	var statusErr *StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, fasthttp.StatusNotFound, statusErr.StatusCode)
+	assert.Equal(t, fasthttp.MethodGet, statusErr.Method)
+	assert.Contains(t, statusErr.URL, "/not-found")
+	assert.Len(t, statusErr.Body, maxErrorBodySize)
+}
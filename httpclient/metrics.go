@@ -0,0 +1,190 @@
+package httpclient
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+)
+
+// defaultMetricsBuckets is the default latency histogram buckets in seconds, same as the Prometheus default buckets.
+var defaultMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MetricsCollector is a thread-safe in-memory Metrics that exposes the metrics in the Prometheus text format:
+//
+//   - httpclient_requests_in_flight{method, route} gauge of the requests in flight.
+//   - httpclient_request_duration_seconds{method, route, status} histogram of the request latency.
+//   - httpclient_request_errors_total{method, route, class} counter of the failed requests by ErrorClass.
+//
+// The status label is "0" if there is no response. The route should be a low-cardinality URL template
+// (see RequestOptions.Route), otherwise every distinct path creates new series.
+//
+//	metrics := httpclient.NewMetricsCollector()
+//	client, err := httpclient.New(baseURL, httpclient.Config{Metrics: metrics})
+//	http.Handle("/metrics", metrics)
+type MetricsCollector struct {
+	mu        sync.Mutex
+	buckets   []float64
+	inFlight  map[metricsKey]int64
+	durations map[metricsKey]*histogram
+	errors    map[metricsKey]uint64
+}
+
+// metricsKey is the labels of a series, the value is the status of durations or the error class of errors.
+type metricsKey struct {
+	method string
+	route  string
+	value  string
+}
+
+type histogram struct {
+	counts []uint64 // non-cumulative count of each bucket, the last one is +Inf.
+	sum    float64
+	count  uint64
+}
+
+// NewMetricsCollector creates a collector with the latency histogram buckets in seconds.
+// Default buckets are the Prometheus default buckets (5ms to 10s) if buckets is empty.
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = defaultMetricsBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &MetricsCollector{
+		buckets:   slices.Compact(buckets),
+		inFlight:  make(map[metricsKey]int64),
+		durations: make(map[metricsKey]*histogram),
+		errors:    make(map[metricsKey]uint64),
+	}
+}
+
+// RequestStarted increases the in-flight gauge of the method and route.
+func (c *MetricsCollector) RequestStarted(method, route string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight[metricsKey{method: method, route: route}]++
+}
+
+// RequestFinished decreases the in-flight gauge, and records the latency and the error class of the request.
+func (c *MetricsCollector) RequestFinished(result RequestResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight[metricsKey{method: result.Method, route: result.Route}]--
+
+	key := metricsKey{method: result.Method, route: result.Route, value: strconv.Itoa(result.StatusCode)}
+	h, ok := c.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets)+1)}
+		c.durations[key] = h
+	}
+	seconds := result.Duration.Seconds()
+	i, _ := slices.BinarySearch(c.buckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+
+	if result.ErrorClass != ErrorClassNone {
+		c.errors[metricsKey{method: result.Method, route: result.Route, value: string(result.ErrorClass)}]++
+	}
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (c *MetricsCollector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	bw.WriteString("# HELP httpclient_requests_in_flight Number of requests in flight.\n")
+	bw.WriteString("# TYPE httpclient_requests_in_flight gauge\n")
+	for _, key := range sortedMetricsKeys(c.inFlight) {
+		writeSample(bw, "httpclient_requests_in_flight", key.labels(""), strconv.FormatInt(c.inFlight[key], 10))
+	}
+
+	bw.WriteString("# HELP httpclient_request_duration_seconds Latency of requests in seconds.\n")
+	bw.WriteString("# TYPE httpclient_request_duration_seconds histogram\n")
+	for _, key := range sortedMetricsKeys(c.durations) {
+		h := c.durations[key]
+		labels := key.labels("status")
+		var cumulative uint64
+		for i, upper := range c.buckets {
+			cumulative += h.counts[i]
+			writeSample(bw, "httpclient_request_duration_seconds_bucket", labels+`,le="`+formatFloat(upper)+`"`,
+				strconv.FormatUint(cumulative, 10))
+		}
+		writeSample(bw, "httpclient_request_duration_seconds_bucket", labels+`,le="+Inf"`, strconv.FormatUint(h.count, 10))
+		writeSample(bw, "httpclient_request_duration_seconds_sum", labels, formatFloat(h.sum))
+		writeSample(bw, "httpclient_request_duration_seconds_count", labels, strconv.FormatUint(h.count, 10))
+	}
+
+	bw.WriteString("# HELP httpclient_request_errors_total Number of failed requests by error class.\n")
+	bw.WriteString("# TYPE httpclient_request_errors_total counter\n")
+	for _, key := range sortedMetricsKeys(c.errors) {
+		writeSample(bw, "httpclient_request_errors_total", key.labels("class"), strconv.FormatUint(c.errors[key], 10))
+	}
+
+	if err := bw.Flush(); err != nil {
+		return cw.n, errors.WithStack(err)
+	}
+	return cw.n, nil
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format, e.g. as the /metrics endpoint.
+func (c *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+// labels returns the labels of the key, valueLabel is the label name of the value (omitted if empty).
+func (k metricsKey) labels(valueLabel string) string {
+	labels := `method="` + escapeLabelValue(k.method) + `",route="` + escapeLabelValue(k.route) + `"`
+	if valueLabel != "" {
+		labels += "," + valueLabel + `="` + escapeLabelValue(k.value) + `"`
+	}
+	return labels
+}
+
+func sortedMetricsKeys[V any](m map[metricsKey]V) []metricsKey {
+	return slices.SortedFunc(maps.Keys(m), func(a, b metricsKey) int {
+		return strings.Compare(a.method+"\x00"+a.route+"\x00"+a.value, b.method+"\x00"+b.route+"\x00"+b.value)
+	})
+}
+
+func writeSample(w *bufio.Writer, name, labels, value string) {
+	w.WriteString(name)
+	w.WriteByte('{')
+	w.WriteString(labels)
+	w.WriteString("} ")
+	w.WriteString(value)
+	w.WriteByte('\n')
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err // nolint: wrapcheck // io.Writer errors are returned as is
+}
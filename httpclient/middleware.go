@@ -0,0 +1,167 @@
+package httpclient
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Cleverse/go-utilities/logger"
+	"github.com/valyala/fasthttp"
+)
+
+// RoundTrip sends the request and fills the response.
+type RoundTrip func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error
+
+// Middleware wraps a RoundTrip to intercept requests and responses,
+// e.g. auth token refresh, request signing, metrics, header injection or custom logging.
+//
+//	client.Use(func(next httpclient.RoundTrip) httpclient.RoundTrip {
+//		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+//			req.Header.Set("Authorization", "Bearer "+token())
+//			return next(ctx, req, resp)
+//		}
+//	})
+type Middleware func(next RoundTrip) RoundTrip
+
+// Use adds middlewares to the client. Middlewares are called in the order they are added,
+// for each attempt of the request (after retry), so they can modify the request before it's sent again.
+//
+// Use is NOT thread-safe, it should be called before the client is used.
+func (h *Client) Use(middlewares ...Middleware) {
+	h.middlewares = append(h.middlewares, middlewares...)
+}
+
+// roundTrip returns the round trip of the client with all built-in and user middlewares.
+//
+// The order of the chain is: debug log -> metrics -> cache -> retry -> hedging -> deadline propagation -> tracing ->
+// rate limit -> circuit breaker -> user middlewares -> transport.
+func (h *Client) roundTrip() RoundTrip {
+	rt := RoundTrip(h.transport.Do)
+	for i := len(h.middlewares) - 1; i >= 0; i-- {
+		rt = h.middlewares[i](rt)
+	}
+	if h.breakers != nil {
+		rt = h.breakers.middleware()(rt)
+	}
+	// outside the circuit breaker, so rejected requests are not recorded as host failures.
+	if h.rateLimiter != nil {
+		rt = h.rateLimiter.middleware()(rt)
+	}
+	rt = traceMiddleware(h.Tracer)(rt)
+	// inside hedging, so each hedged request sends its own remaining time.
+	if h.DeadlineHeader != "" {
+		rt = deadlineMiddleware(h.DeadlineHeader)(rt)
+	}
+	if h.Hedge.Delay > 0 {
+		rt = h.Hedge.middleware(h.Retry.RetryStatusCodes)(rt)
+	}
+	rt = h.Retry.middleware()(rt)
+	if h.cache != nil {
+		rt = h.cache.middleware()(rt)
+	}
+	if h.Metrics != nil {
+		rt = metricsMiddleware(h.Metrics)(rt)
+	}
+	if h.Debug {
+		rt = debugMiddleware(rt)
+	}
+	return rt
+}
+
+// debugMiddleware logs the request and response details when the request is finished.
+func debugMiddleware(next RoundTrip) RoundTrip {
+	return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+		startDo := time.Now()
+		err := next(ctx, req, resp)
+
+		state := requestStateFromContext(ctx)
+		ctx = logger.WithContext(ctx,
+			slog.String("method", string(req.Header.Method())),
+			slog.String("url", req.URI().String()),
+			slog.Duration("duration", time.Since(state.start)),
+			slog.Duration("latency", time.Since(startDo)),
+			slog.Int("attempts", state.attempts),
+			slog.Int("hedges", state.hedges),
+			slog.Duration("timeout", state.timeout),
+			slog.Duration("propagated_deadline", time.Duration(state.propagatedDeadline.Load())),
+			slog.Int("req_header_size", len(req.Header.Header())),
+			slog.Int("req_content_length", req.Header.ContentLength()),
+		)
+
+		if state.cache != "" {
+			ctx = logger.WithContext(ctx, slog.String("cache", state.cache))
+		}
+
+		if resp.StatusCode() >= 0 {
+			// don't read the body stream, it's read by the caller.
+			respContentLength := resp.Header.ContentLength()
+			if !resp.IsBodyStream() {
+				respContentLength = len(resp.Body())
+			}
+			ctx = logger.WithContext(ctx,
+				slog.Int("status_code", resp.StatusCode()),
+				slog.String("resp_content_type", string(resp.Header.ContentType())),
+				slog.String("resp_content_encoding", string(resp.Header.ContentEncoding())),
+				slog.Int("resp_content_length", respContentLength),
+			)
+		}
+
+		logger.InfoContext(ctx, "Finished make request", slog.String("package", "httpclient"))
+		return err
+	}
+}
+
+// deadlineMiddleware sends the remaining time until the context deadline in milliseconds in the header of each attempt,
+// so the server can stop working on requests that the client has given up on.
+func deadlineMiddleware(header string) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			if deadline, ok := ctx.Deadline(); ok {
+				remaining := max(time.Until(deadline), 0)
+				req.Header.Set(header, strconv.FormatInt(remaining.Milliseconds(), 10))
+				requestStateFromContext(ctx).propagatedDeadline.Store(int64(remaining))
+			}
+			return next(ctx, req, resp)
+		}
+	}
+}
+
+type requestStateKey struct{}
+
+// requestState holds the state of a request shared between built-in middlewares.
+type requestState struct {
+	start    time.Time
+	attempts int
+
+	// number of hedged requests sent in addition to the attempts.
+	hedges int
+
+	// timeout of the request from RequestOptions.Timeout.
+	timeout time.Duration
+
+	// remaining time until the deadline sent in Config.DeadlineHeader of the last attempt as time.Duration.
+	// it's atomic because hedged requests set it concurrently.
+	propagatedDeadline atomic.Int64
+
+	// cache status of the request (hit, miss, revalidated or bypass), empty if the cache is disabled.
+	cache string
+
+	// URL template of the request from RequestOptions.Route.
+	route string
+}
+
+func withRequestState(ctx context.Context, start time.Time) (context.Context, *requestState) {
+	state := &requestState{start: start}
+	return context.WithValue(ctx, requestStateKey{}, state), state
+}
+
+// requestStateFromContext returns the request state from the context.
+// returns a new state if not found, e.g. when the round trip is called outside of the client.
+func requestStateFromContext(ctx context.Context) *requestState {
+	if state, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+		return state
+	}
+	return &requestState{start: time.Now()}
+}
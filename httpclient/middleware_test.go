@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	var count atomic.Int32
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		if count.Add(1) == 1 {
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			return
+		}
+		ctx.Response.Header.Set("X-Token", string(ctx.Request.Header.Peek("Authorization")))
+	})
+
+	client, err := New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	var (
+		order []string
+		calls atomic.Int32
+	)
+	client.Use(
+		func(next RoundTrip) RoundTrip {
+			return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+				order = append(order, "first")
+				n := calls.Add(1)
+				req.Header.Set("Authorization", "token-"+string(rune('0'+n)))
+				return next(ctx, req, resp)
+			}
+		},
+		func(next RoundTrip) RoundTrip {
+			return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+				order = append(order, "second")
+				return next(ctx, req, resp)
+			}
+		},
+	)
+
+	resp, err := client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+
+	// middlewares are called for each attempt, in the order they are added.
+	assert.Equal(t, []string{"first", "second", "first", "second"}, order)
+	assert.Equal(t, "token-2", string(resp.Header.Peek("X-Token")))
+}
+
+func TestDebugMiddleware(t *testing.T) {
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		ctx.WriteString("{}")
+	})
+
+	var buf bytes.Buffer
+	defaultLogger := logger.GetLogger()
+	logger.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer logger.SetLogger(defaultLogger)
+
+	client, err := New(baseURL, Config{Debug: true})
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), "/debug", RequestOptions{})
+	require.NoError(t, err)
+
+	log := buf.String()
+	assert.Contains(t, log, "Finished make request")
+	assert.Contains(t, log, "method=GET")
+	assert.Contains(t, log, "/debug")
+	assert.Contains(t, log, "status_code=200")
+	assert.Contains(t, log, "attempts=1")
+}
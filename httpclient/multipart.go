@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Multipart is a multipart/form-data request body with form fields and file parts.
+//
+// The parts are encoded into memory before the request is sent, so the request can be retried.
+//
+//	resp, err := client.Post(ctx, "/upload", httpclient.RequestOptions{
+//		Multipart: &httpclient.Multipart{
+//			Fields: url.Values{"name": {"avatar"}},
+//			Files: []httpclient.MultipartFile{
+//				{FieldName: "file", FileName: "avatar.png", ContentType: "image/png", Reader: file},
+//			},
+//		},
+//	})
+type Multipart struct {
+	// Form fields, written before the files in the order of the sorted keys.
+	Fields url.Values
+
+	// File parts, written in order.
+	Files []MultipartFile
+}
+
+// MultipartFile is a file part of a multipart/form-data request body.
+type MultipartFile struct {
+	// Name of the form field.
+	FieldName string
+
+	// Name of the file sent in the Content-Disposition header.
+	FileName string
+
+	// Content type of the file, default is application/octet-stream.
+	ContentType string
+
+	// Content of the file. It's read until EOF and is not closed.
+	Reader io.Reader
+}
+
+// encode writes the multipart body and returns it with the content type (including the boundary).
+func (m *Multipart) encode() ([]byte, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for _, key := range slices.Sorted(maps.Keys(m.Fields)) {
+		for _, value := range m.Fields[key] {
+			if err := w.WriteField(key, value); err != nil {
+				return nil, "", errors.Wrapf(err, "can't write multipart field %q", key)
+			}
+		}
+	}
+
+	for _, file := range m.Files {
+		if file.Reader == nil {
+			return nil, "", errors.Errorf("multipart file %q of field %q has no reader", file.FileName, file.FieldName)
+		}
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", multipart.FileContentDisposition(file.FieldName, file.FileName))
+		header.Set("Content-Type", contentType)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "can't create multipart file %q of field %q", file.FileName, file.FieldName)
+		}
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return nil, "", errors.Wrapf(err, "can't read multipart file %q of field %q", file.FileName, file.FieldName)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", errors.Wrap(err, "can't close multipart writer")
+	}
+	return body.Bytes(), w.FormDataContentType(), nil
+}
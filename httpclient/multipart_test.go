@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+type multipartTestFile struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type multipartTestResponse struct {
+	Fields map[string][]string            `json:"fields"`
+	Files  map[string][]multipartTestFile `json:"files"`
+}
+
+func TestMultipart(t *testing.T) {
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+
+		response := multipartTestResponse{
+			Fields: form.Value,
+			Files:  make(map[string][]multipartTestFile),
+		}
+		for field, headers := range form.File {
+			for _, header := range headers {
+				file, err := header.Open()
+				if err != nil {
+					ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+					return
+				}
+				content, _ := io.ReadAll(file)
+				file.Close()
+				response.Files[field] = append(response.Files[field], multipartTestFile{
+					FileName:    header.Filename,
+					ContentType: header.Header.Get("Content-Type"),
+					Content:     string(content),
+				})
+			}
+		}
+		ctx.SetContentType("application/json")
+		json.NewEncoder(ctx).Encode(response)
+	})
+
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	resp, err := client.Post(context.Background(), "/upload", RequestOptions{
+		Multipart: &Multipart{
+			Fields: url.Values{"name": {"report"}, "tags": {"a", "b"}},
+			Files: []MultipartFile{
+				{FieldName: "image", FileName: "avatar.png", ContentType: "image/png", Reader: strings.NewReader("png")},
+				{FieldName: "export", FileName: `"export".csv`, Reader: strings.NewReader("id,name\n1,foo\n")},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+
+	var result multipartTestResponse
+	require.NoError(t, resp.UnmarshalBody(&result))
+	assert.Equal(t, map[string][]string{"name": {"report"}, "tags": {"a", "b"}}, result.Fields)
+	assert.Equal(t, map[string][]multipartTestFile{
+		"image":  {{FileName: "avatar.png", ContentType: "image/png", Content: "png"}},
+		"export": {{FileName: `"export".csv`, ContentType: "application/octet-stream", Content: "id,name\n1,foo\n"}},
+	}, result.Files)
+}
+
+func TestMultipartError(t *testing.T) {
+	client, err := New("http://127.0.0.1:0")
+	require.NoError(t, err)
+
+	_, err = client.Post(context.Background(), "/upload", RequestOptions{
+		Multipart: &Multipart{
+			Files: []MultipartFile{{FieldName: "file", FileName: "empty.txt"}},
+		},
+	})
+	assert.ErrorContains(t, err, "has no reader")
+}
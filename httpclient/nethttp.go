@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// NewFromHTTPClient creates a client that sends requests with the net/http client, e.g. for HTTP/2,
+// proxies from the environment (HTTP_PROXY) or httptest.Server. http.DefaultClient is used if client is nil.
+//
+// The request options, responses and middlewares work the same as with the fasthttp client,
+// except that redirects and compression are handled by the net/http client.
+func NewFromHTTPClient(client *http.Client, baseURL string, config ...Config) (*Client, error) {
+	return NewFromTransport(NewHTTPTransport(client), baseURL, config...)
+}
+
+// NewHTTPTransport returns a transport that sends requests with the net/http client.
+// http.DefaultClient is used if client is nil.
+func NewHTTPTransport(client *http.Client) Transport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpTransport{client: client}
+}
+
+// httpTransport sends requests with a net/http client, converting the fasthttp request and response.
+type httpTransport struct {
+	client *http.Client
+}
+
+// skipped request headers, they are set by net/http from the request fields.
+var httpTransportSkipHeaders = map[string]bool{
+	fasthttp.HeaderHost:             true,
+	fasthttp.HeaderContentLength:    true,
+	fasthttp.HeaderTransferEncoding: true,
+	fasthttp.HeaderConnection:       true,
+}
+
+// Do sends the request with the net/http client.
+//
+// The request body is read through a guard that is aborted when Do returns,
+// because net/http may still read the body in the background after the response is received.
+func (t *httpTransport) Do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	var body *guardedReader
+	contentLength := int64(req.Header.ContentLength())
+	switch {
+	case req.IsBodyStream():
+		body = &guardedReader{r: req.BodyStream()}
+	case len(req.Body()) > 0:
+		body = &guardedReader{r: bytes.NewReader(req.Body())}
+		contentLength = int64(len(req.Body()))
+	}
+
+	var httpBody io.Reader
+	if body != nil {
+		httpBody = body
+		defer body.abort(errors.New("request is finished"))
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, string(req.Header.Method()), req.URI().String(), httpBody)
+	if err != nil {
+		return errors.Wrap(err, "can't create net/http request")
+	}
+	if body != nil {
+		// chunked transfer encoding if the length is unknown.
+		httpReq.ContentLength = max(contentLength, -1)
+	}
+	if host := req.Header.Host(); len(host) > 0 {
+		httpReq.Host = string(host)
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		if !httpTransportSkipHeaders[string(key)] {
+			httpReq.Header.Add(string(key), string(value))
+		}
+	})
+
+	httpResp, err := t.client.Do(httpReq)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	resp.SetStatusCode(httpResp.StatusCode)
+	for key, values := range httpResp.Header {
+		if key == fasthttp.HeaderContentLength {
+			continue
+		}
+		for _, value := range values {
+			resp.Header.Add(key, value)
+		}
+	}
+
+	if resp.StreamBody {
+		// the body is closed when the response body stream is closed.
+		resp.SetBodyStream(httpResp.Body, int(httpResp.ContentLength))
+		return nil
+	}
+
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return errors.Wrap(err, "can't read response body")
+	}
+	resp.SetBody(respBody)
+	return nil
+}
@@ -0,0 +1,122 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func newHTTPTestServer(t *testing.T, http2 bool) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Header().Set("X-Proto", r.Proto)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Query", r.URL.RawQuery)
+		w.Header().Set("X-Test", r.Header.Get("X-Test"))
+		w.Header().Add("X-Multi", "a")
+		w.Header().Add("X-Multi", "b")
+		w.Write(body)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("/unavailable", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	if http2 {
+		server.EnableHTTP2 = true
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNetHTTPTransport(t *testing.T) {
+	for name, http2 := range map[string]bool{"HTTP/1.1": false, "HTTP/2.0": true} {
+		t.Run(name, func(t *testing.T) {
+			server := newHTTPTestServer(t, http2)
+			client, err := NewFromHTTPClient(server.Client(), server.URL, Config{
+				Headers: map[string]string{"X-Test": "default"},
+			})
+			require.NoError(t, err)
+			ctx := context.Background()
+
+			t.Run("request", func(t *testing.T) {
+				resp, err := client.Post(ctx, "/echo", RequestOptions{
+					Payload: testResponse{Message: "hello"},
+					Query:   map[string][]string{"q": {"1"}},
+				})
+				require.NoError(t, err)
+				assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+				assert.Equal(t, name, string(resp.Header.Peek("X-Proto")))
+				assert.Equal(t, fasthttp.MethodPost, string(resp.Header.Peek("X-Method")))
+				assert.Equal(t, "q=1", string(resp.Header.Peek("X-Query")))
+				assert.Equal(t, "default", string(resp.Header.Peek("X-Test")))
+
+				var multi []string
+				for _, value := range resp.Header.PeekAll("X-Multi") {
+					multi = append(multi, string(value))
+				}
+				assert.Equal(t, []string{"a", "b"}, multi)
+
+				var result testResponse
+				require.NoError(t, resp.UnmarshalBody(&result))
+				assert.Equal(t, "hello", result.Message)
+			})
+
+			t.Run("body stream", func(t *testing.T) {
+				body := strings.Repeat("a", streamTestSize)
+				resp, err := client.DoStream(ctx, fasthttp.MethodPut, "/echo", RequestOptions{
+					BodyStream:  strings.NewReader(body),
+					ContentType: ContentTypeText,
+				})
+				require.NoError(t, err)
+				defer resp.Close()
+
+				got, err := io.ReadAll(resp)
+				require.NoError(t, err)
+				assert.Equal(t, body, string(got))
+			})
+
+			t.Run("status error", func(t *testing.T) {
+				client, err := NewFromHTTPClient(server.Client(), server.URL, Config{ReturnStatusError: true})
+				require.NoError(t, err)
+
+				_, err = client.Get(ctx, "/unavailable", RequestOptions{})
+				var statusErr *StatusError
+				require.ErrorAs(t, err, &statusErr)
+				assert.Equal(t, "maintenance\n", string(statusErr.Body))
+				assert.True(t, errors.Is(err, errs.Unavailable))
+			})
+
+			t.Run("context timeout", func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				defer cancel()
+
+				_, err := client.Get(ctx, "/slow", RequestOptions{})
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			})
+		})
+	}
+}
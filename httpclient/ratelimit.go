@@ -0,0 +1,173 @@
+package httpclient
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// RateLimitConfig is the configuration of client-side rate limiting and concurrency limiting.
+// Both limits are disabled by default.
+type RateLimitConfig struct {
+	// Maximum requests per second (token bucket refill rate). Rate limit is disabled if RequestsPerSecond <= 0.
+	RequestsPerSecond float64
+
+	// Maximum burst size of the token bucket. Default is ceil(RequestsPerSecond).
+	Burst int
+
+	// Maximum number of in-flight requests. Concurrency limit is disabled if MaxInFlight <= 0.
+	MaxInFlight int
+
+	// Apply the limits to each host separately instead of the whole client.
+	PerHost bool
+}
+
+func (c RateLimitConfig) enabled() bool {
+	return c.RequestsPerSecond > 0 || c.MaxInFlight > 0
+}
+
+// rateLimiter limits the requests of a client (or each host if PerHost is enabled).
+type rateLimiter struct {
+	config RateLimitConfig
+	mu     sync.Mutex
+	hosts  map[string]*hostLimiter
+}
+
+type hostLimiter struct {
+	bucket   *tokenBucket
+	inFlight chan struct{}
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	if !config.enabled() {
+		return nil
+	}
+	if config.Burst <= 0 {
+		config.Burst = max(int(math.Ceil(config.RequestsPerSecond)), 1)
+	}
+	return &rateLimiter{
+		config: config,
+		hosts:  make(map[string]*hostLimiter),
+	}
+}
+
+func (l *rateLimiter) host(host string) *hostLimiter {
+	if !l.config.PerHost {
+		host = ""
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hl, ok := l.hosts[host]
+	if !ok {
+		hl = &hostLimiter{}
+		if l.config.RequestsPerSecond > 0 {
+			hl.bucket = newTokenBucket(l.config.RequestsPerSecond, l.config.Burst)
+		}
+		if l.config.MaxInFlight > 0 {
+			hl.inFlight = make(chan struct{}, l.config.MaxInFlight)
+		}
+		l.hosts[host] = hl
+	}
+	return hl
+}
+
+// middleware returns a middleware that waits for the rate limit and the concurrency limit before sending the request.
+func (l *rateLimiter) middleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			hl := l.host(string(req.URI().Host()))
+
+			if hl.bucket != nil {
+				if err := hl.bucket.wait(ctx); err != nil {
+					return err
+				}
+			}
+
+			if hl.inFlight != nil {
+				select {
+				case hl.inFlight <- struct{}{}:
+					defer func() { <-hl.inFlight }()
+				case <-ctx.Done():
+					return errors.Mark(errors.Wrap(ctx.Err(), "waiting for in-flight requests limit"), errs.RateLimitExceeded)
+				}
+			}
+
+			return next(ctx, req, resp)
+		}
+	}
+}
+
+// tokenBucket is a thread-safe token bucket rate limiter.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns the duration to wait before the token is available.
+// The token is NOT taken if the wait duration exceeds maxWait.
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (wait time.Duration, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elapsed := max(now.Sub(b.last), 0)
+	tokens := min(b.burst, b.tokens+elapsed.Seconds()*b.rate) - 1
+	if tokens < 0 {
+		wait = time.Duration(-tokens / b.rate * float64(time.Second))
+	}
+	if wait > maxWait {
+		return wait, false
+	}
+
+	b.tokens = tokens
+	if now.After(b.last) {
+		b.last = now
+	}
+	return wait, true
+}
+
+// wait waits until a token is available or the context is done.
+// returns an error marked with errs.RateLimitExceeded immediately if the wait would exceed the context deadline.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	now := time.Now()
+	maxWait := time.Duration(math.MaxInt64)
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = deadline.Sub(now)
+	}
+
+	wait, ok := b.reserve(now, maxWait)
+	if !ok {
+		return errors.Wrapf(errs.RateLimitExceeded, "rate limit wait %v exceeds context deadline", wait)
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.Mark(errors.Wrap(ctx.Err(), "waiting for rate limit"), errs.RateLimitExceeded)
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(10, 2)
+	bucket.last = now
+
+	// burst
+	for i := 0; i < 2; i++ {
+		wait, ok := bucket.reserve(now, time.Second)
+		assert.True(t, ok)
+		assert.Zero(t, wait)
+	}
+
+	wait, ok := bucket.reserve(now, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	// the wait exceeds maxWait, the token should not be taken.
+	wait, ok = bucket.reserve(now, 100*time.Millisecond)
+	assert.False(t, ok)
+	assert.Equal(t, 200*time.Millisecond, wait)
+
+	wait, ok = bucket.reserve(now.Add(300*time.Millisecond), time.Second)
+	assert.True(t, ok)
+	assert.Zero(t, wait)
+}
+
+func TestRateLimit(t *testing.T) {
+	var (
+		inFlight    atomic.Int32
+		maxInFlight atomic.Int32
+	)
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	t.Run("requests per second", func(t *testing.T) {
+		client, err := New(baseURL, Config{
+			RateLimit: RateLimitConfig{RequestsPerSecond: 20, Burst: 1},
+		})
+		require.NoError(t, err)
+
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			_, err := client.Get(context.Background(), "/", RequestOptions{})
+			require.NoError(t, err)
+		}
+		// first request is allowed by the burst, the rest wait 50ms each.
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		client, err := New(baseURL, Config{
+			RateLimit: RateLimitConfig{RequestsPerSecond: 1, Burst: 1},
+		})
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background(), "/", RequestOptions{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err = client.Get(ctx, "/", RequestOptions{})
+		assert.ErrorIs(t, err, errs.RateLimitExceeded)
+		assert.Less(t, time.Since(start), 100*time.Millisecond, "should fail fast when the wait exceeds the deadline")
+	})
+
+	t.Run("max in-flight", func(t *testing.T) {
+		maxInFlight.Store(0)
+		client, err := New(baseURL, Config{
+			RateLimit: RateLimitConfig{MaxInFlight: 2},
+		})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.Get(context.Background(), "/", RequestOptions{})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	})
+
+	t.Run("per host", func(t *testing.T) {
+		limiter := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, PerHost: true})
+		assert.NotSame(t, limiter.host("a.example.com"), limiter.host("b.example.com"))
+		assert.Same(t, limiter.host("a.example.com"), limiter.host("a.example.com"))
+
+		limiter = newRateLimiter(RateLimitConfig{RequestsPerSecond: 1})
+		assert.Same(t, limiter.host("a.example.com"), limiter.host("b.example.com"))
+	})
+}
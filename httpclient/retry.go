@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+)
+
+// defaultRetryStatusCodes is the default response status codes that should be retried.
+var defaultRetryStatusCodes = []int{
+	fasthttp.StatusTooManyRequests,
+	fasthttp.StatusBadGateway,
+	fasthttp.StatusServiceUnavailable,
+	fasthttp.StatusGatewayTimeout,
+}
+
+// RetryPolicy is the configuration of request retry with exponential backoff and jitter.
+// Retry is disabled by default (MaxAttempts <= 1).
+type RetryPolicy struct {
+	// Maximum number of attempts including the first request. Retry is disabled if MaxAttempts <= 1.
+	MaxAttempts int
+
+	// Backoff before the first retry, the backoff is doubled for each subsequent retry. Default is 100ms.
+	InitialBackoff time.Duration
+
+	// Maximum backoff between retries. Default is 10s.
+	// If the Retry-After header of the response exceeds MaxBackoff, the request is not retried and the response is returned.
+	MaxBackoff time.Duration
+
+	// Disable random jitter of the backoff. By default, the backoff is randomized between 50% and 100% of its value.
+	DisableJitter bool
+
+	// Response status codes that should be retried. Default is 429, 502, 503 and 504.
+	RetryStatusCodes []int
+
+	// Retry non-idempotent methods (POST, PATCH) as well. By default, only idempotent methods are retried.
+	RetryNonIdempotent bool
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if len(p.RetryStatusCodes) == 0 {
+		p.RetryStatusCodes = defaultRetryStatusCodes
+	}
+	return p
+}
+
+// canRetry returns true if the request method is allowed to be retried.
+func (p RetryPolicy) canRetry(method string) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	return p.RetryNonIdempotent || isIdempotentMethod(method)
+}
+
+// shouldRetry returns true if the result of the attempt should be retried.
+func (p RetryPolicy) shouldRetry(ctx context.Context, attempt int, resp *fasthttp.Response, err error) bool {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		// client-side rate limit errors are not retried, the limiter already waited as long as possible.
+		// and open circuit errors are not retried, the host is known to be unavailable.
+		return !errors.Is(err, errs.RateLimitExceeded) && !errors.Is(err, ErrCircuitOpen)
+	}
+	return slices.Contains(p.RetryStatusCodes, resp.StatusCode())
+}
+
+// backoff returns the wait duration before the next attempt.
+// The Retry-After response header is honored if it's present, it may exceed MaxBackoff.
+func (p RetryPolicy) backoff(attempt int, resp *fasthttp.Response) time.Duration {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Peek(fasthttp.HeaderRetryAfter)); ok {
+			return retryAfter
+		}
+	}
+
+	backoff := p.InitialBackoff << (attempt - 1)
+	if backoff <= 0 || backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if !p.DisableJitter {
+		backoff = backoff/2 + rand.N(backoff/2+1)
+	}
+	return backoff
+}
+
+// parseRetryAfter parses the Retry-After header value in delay-seconds or HTTP-date format.
+func parseRetryAfter(value []byte) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(string(value)); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := fasthttp.ParseHTTPDate(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// middleware returns a middleware that retries the next round trip by the policy.
+func (p RetryPolicy) middleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			state := requestStateFromContext(ctx)
+			// the body stream is consumed by the first attempt, it can't be sent again.
+			canRetry := p.canRetry(string(req.Header.Method())) && !req.IsBodyStream()
+			streamBody := resp.StreamBody
+			for attempt := 1; ; attempt++ {
+				state.attempts = attempt
+				resp.Reset()
+				resp.StreamBody = streamBody
+
+				err := next(ctx, req, resp)
+				if !canRetry || !p.shouldRetry(ctx, attempt, resp, err) {
+					return err
+				}
+
+				var backoffResp *fasthttp.Response
+				if err == nil {
+					backoffResp = resp
+				}
+				if sleepErr := p.sleep(ctx, attempt, backoffResp); sleepErr != nil {
+					// can't wait for the next attempt, return the last result.
+					return err
+				}
+			}
+		}
+	}
+}
+
+// sleep waits for the backoff before the next attempt or until the context is done.
+func (p RetryPolicy) sleep(ctx context.Context, attempt int, resp *fasthttp.Response) error {
+	d := p.backoff(attempt, resp)
+	if d > p.MaxBackoff {
+		return errors.Errorf("retry backoff %v exceeds max backoff %v", d, p.MaxBackoff)
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return errors.Wrapf(context.DeadlineExceeded, "retry backoff %v exceeds context deadline", d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case fasthttp.MethodGet, fasthttp.MethodHead, fasthttp.MethodOptions, fasthttp.MethodTrace,
+		fasthttp.MethodPut, fasthttp.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
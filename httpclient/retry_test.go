@@ -0,0 +1,143 @@
+package httpclient
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func newRetryTestServer(t *testing.T, failures int32, statusCode int, retryAfter string) (string, *atomic.Int32) {
+	t.Helper()
+
+	var count atomic.Int32
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		if count.Add(1) <= failures {
+			if retryAfter != "" {
+				ctx.Response.Header.Set(fasthttp.HeaderRetryAfter, retryAfter)
+			}
+			ctx.SetStatusCode(statusCode)
+			return
+		}
+		ctx.SetContentType("application/json")
+		ctx.WriteString(`{"message":"success"}`)
+	})
+
+	return baseURL, &count
+}
+
+func TestRetry(t *testing.T) {
+	baseURL, count := newRetryTestServer(t, 2, fasthttp.StatusServiceUnavailable, "")
+
+	client, err := New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	resp, err := client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+	assert.Equal(t, int32(3), count.Load())
+}
+
+func TestRetryExhausted(t *testing.T) {
+	baseURL, count := newRetryTestServer(t, 5, fasthttp.StatusBadGateway, "")
+
+	client, err := New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	// the last response is returned when all attempts are exhausted.
+	resp, err := client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusBadGateway, resp.StatusCode())
+	assert.Equal(t, int32(2), count.Load())
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	baseURL, count := newRetryTestServer(t, 1, fasthttp.StatusServiceUnavailable, "")
+
+	client, err := New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	resp, err := client.Post(context.Background(), "/", RequestOptions{Body: []byte(`{}`)})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, int32(1), count.Load(), "POST should not be retried by default")
+
+	client.Retry.RetryNonIdempotent = true
+	resp, err = client.Post(context.Background(), "/", RequestOptions{Body: []byte(`{}`)})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+}
+
+func TestRetryAfter(t *testing.T) {
+	baseURL, count := newRetryTestServer(t, 1, fasthttp.StatusTooManyRequests, "1")
+
+	client, err := New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+	assert.Equal(t, int32(2), count.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	// Retry-After exceeds the context deadline, the last response is returned immediately.
+	baseURL, _ = newRetryTestServer(t, 1, fasthttp.StatusTooManyRequests, "60")
+	client, err = New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 2},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err = client.Get(ctx, "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusTooManyRequests, resp.StatusCode())
+
+	// Retry-After exceeds MaxBackoff, the last response is returned immediately even without deadline.
+	baseURL, count = newRetryTestServer(t, 1, fasthttp.StatusServiceUnavailable, "3600")
+	client, err = New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 2, MaxBackoff: time.Second},
+	})
+	require.NoError(t, err)
+
+	start = time.Now()
+	resp, err = client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, int32(1), count.Load())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		DisableJitter:  true,
+	}.withDefaults()
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1, nil))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2, nil))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3, nil))
+	assert.Equal(t, time.Second, policy.backoff(10, nil))
+	assert.Equal(t, time.Second, policy.backoff(100, nil))
+
+	policy.DisableJitter = false
+	for attempt := 1; attempt <= 5; attempt++ {
+		backoff := policy.backoff(attempt, nil)
+		assert.GreaterOrEqual(t, backoff, min(100*time.Millisecond<<(attempt-1), time.Second)/2)
+		assert.LessOrEqual(t, backoff, min(100*time.Millisecond<<(attempt-1), time.Second))
+	}
+}
@@ -0,0 +1,22 @@
+package httpclient
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// newTestServer serves the handler on a local port until the test is finished, returns the base URL of the server.
+func newTestServer(t *testing.T, handler fasthttp.RequestHandler) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go (&fasthttp.Server{Handler: handler}).Serve(ln)
+
+	return "http://" + ln.Addr().String()
+}
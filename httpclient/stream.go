@@ -0,0 +1,307 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// ErrStreamClosed is returned when reading from a closed StreamResponse.
+//
+// inherited error from errs.Closed,
+// so errors.Is(err, errs.Closed) == true
+var ErrStreamClosed = errors.Wrap(errs.Closed, "stream response is closed")
+
+// StreamResponse is a response with a streamed body, the body is read from the connection
+// while reading the response instead of loading it into memory.
+//
+// StreamResponse implements io.ReadCloser for the response body. The body is not uncompressed.
+// It MUST be closed after use to release the connection and the pooled response.
+//
+//	resp, err := client.DoStream(ctx, fasthttp.MethodGet, "/snapshot", httpclient.RequestOptions{})
+//	if err != nil {
+//		return errors.WithStack(err)
+//	}
+//	defer resp.Close()
+//	_, err = io.Copy(file, resp)
+type StreamResponse struct {
+	URL string
+
+	resp   *fasthttp.Response
+	body   io.Reader
+	cancel context.CancelFunc
+
+	// readMu serializes Read, mu guards the state and is not held across the read of the body,
+	// so Close can interrupt a stalled Read.
+	readMu  sync.Mutex
+	mu      sync.Mutex
+	closed  bool
+	reading bool
+}
+
+func newStreamResponse(url string, resp *fasthttp.Response, cancel context.CancelFunc) *StreamResponse {
+	body := resp.BodyStream()
+	if body == nil {
+		// response without body, e.g. HEAD or 204 No Content.
+		body = bytes.NewReader(resp.Body())
+	}
+	return &StreamResponse{
+		URL:    url,
+		resp:   resp,
+		body:   body,
+		cancel: cancel,
+	}
+}
+
+// StatusCode returns the response status code.
+func (r *StreamResponse) StatusCode() int {
+	return r.resp.StatusCode()
+}
+
+// Header returns the response header. It must not be used after Close.
+func (r *StreamResponse) Header() *fasthttp.ResponseHeader {
+	return &r.resp.Header
+}
+
+// Read reads the response body.
+func (r *StreamResponse) Read(p []byte) (int, error) {
+	r.readMu.Lock()
+	defer r.readMu.Unlock()
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return 0, errors.WithStack(ErrStreamClosed)
+	}
+	r.reading = true
+	r.mu.Unlock()
+
+	n, err := r.body.Read(p)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reading = false
+	if r.closed {
+		// closed while reading, the response is released here instead of Close.
+		_ = r.release()
+		return 0, errors.WithStack(ErrStreamClosed)
+	}
+	return n, err // nolint: wrapcheck // io.EOF must not be wrapped
+}
+
+// Close closes the body stream and releases the response. It's safe to call Close multiple times.
+//
+// Close cancels the context of the request to interrupt an in-flight Read from another goroutine,
+// the response is released when the Read returns.
+func (r *StreamResponse) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.cancel()
+	if r.reading {
+		return nil
+	}
+	return r.release()
+}
+
+// release closes the body stream and releases the response, the caller must hold r.mu.
+func (r *StreamResponse) release() error {
+	err := r.resp.CloseBodyStream()
+	fasthttp.ReleaseResponse(r.resp)
+	r.resp = nil
+	return errors.WithStack(err)
+}
+
+// DoStream sends the request and returns the response with a streamed body. The caller must close the response.
+//
+// The middlewares are applied as usual, but the response body is not available to them.
+// If Config.ReturnStatusError is set, a *StatusError with the first bytes of the body is returned
+// for non-2xx responses, and the response is closed.
+func (h *Client) DoStream(ctx context.Context, method, path string, reqOptions RequestOptions) (*StreamResponse, error) {
+	reqOptions.path = path
+	reqOptions.method = method
+	return h.stream(ctx, reqOptions)
+}
+
+func (h *Client) stream(ctx context.Context, reqOptions RequestOptions) (*StreamResponse, error) {
+	start := time.Now()
+	req, requestUrl, err := h.newRequest(reqOptions)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer fasthttp.ReleaseRequest(req)
+
+	resp := fasthttp.AcquireResponse()
+	resp.StreamBody = true
+
+	// the timeout covers reading the body, the context is cancelled when the response is closed
+	// to interrupt an in-flight read of the body.
+	var cancel context.CancelFunc
+	if reqOptions.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, reqOptions.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	ctx, state := withRequestState(ctx, start)
+	state.timeout = reqOptions.Timeout
+	state.route = reqOptions.Route
+	if err := h.roundTrip()(ctx, req, resp); err != nil {
+		cancel()
+		fasthttp.ReleaseResponse(resp)
+		return nil, errors.Wrapf(err, "error during request: url: %s, attempts: %d", requestUrl, state.attempts)
+	}
+
+	streamResponse := newStreamResponse(requestUrl, resp, cancel)
+	if h.ReturnStatusError && !isSuccessStatus(resp.StatusCode()) {
+		defer streamResponse.Close()
+
+		// read only the part of the body that is kept in the error.
+		body, _ := io.ReadAll(io.LimitReader(streamResponse, maxErrorBodySize))
+		httpResponse := HttpResponse{
+			URL: requestUrl,
+		}
+		resp.Header.CopyTo(&httpResponse.Header)
+		httpResponse.SetBody(body)
+		return nil, errors.WithStack(newStatusError(reqOptions.method, &httpResponse))
+	}
+
+	return streamResponse, nil
+}
+
+// DoStream is a shortcut for New(path).DoStream(ctx, method, "", reqOptions)
+func DoStream(ctx context.Context, method, path string, reqOptions RequestOptions) (*StreamResponse, error) {
+	client, err := New(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return client.DoStream(ctx, method, "", reqOptions)
+}
+
+// guardedReader shares a request body stream with a background request,
+// it stops reading from the underlying reader after abort.
+type guardedReader struct {
+	r   io.Reader
+	mu  sync.Mutex
+	err error
+}
+
+func (g *guardedReader) Read(p []byte) (int, error) {
+	g.mu.Lock()
+	err := g.err
+	g.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	return g.r.Read(p) // nolint: wrapcheck // io.EOF must not be wrapped
+}
+
+// abort makes the next reads fail with err. It doesn't wait for an in-flight Read,
+// because the underlying reader may block until the caller stops waiting for the request.
+func (g *guardedReader) abort(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.err = err
+}
+
+// pooledBodyStream is the body stream of a pooled response that is moved to another response.
+//
+// fasthttp can't interrupt a read of the body stream, so the body is read in the background and Read returns
+// when the context is done, like a net/http response body. Closing it closes the body stream and releases
+// the pooled response, or leaves them to the in-flight read. It's closed by CloseBodyStream or Reset of
+// the response that owns it.
+type pooledBodyStream struct {
+	ctx    context.Context
+	resp   *fasthttp.Response
+	result chan bodyReadResult
+
+	mu      sync.Mutex
+	buf     []byte
+	reading bool
+	closed  bool
+}
+
+type bodyReadResult struct {
+	n   int
+	err error
+}
+
+func newPooledBodyStream(ctx context.Context, resp *fasthttp.Response) *pooledBodyStream {
+	return &pooledBodyStream{
+		ctx:    ctx,
+		resp:   resp,
+		result: make(chan bodyReadResult, 1),
+	}
+}
+
+func (s *pooledBodyStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, errors.WithStack(ErrStreamClosed)
+	}
+	// the read interrupted by the context may be still in-flight, don't start another one.
+	if err := s.ctx.Err(); err != nil {
+		s.mu.Unlock()
+		return 0, errors.WithStack(err)
+	}
+	// read into our own buffer, p must not be written after Read returns.
+	if cap(s.buf) < len(p) {
+		s.buf = make([]byte, len(p))
+	}
+	buf := s.buf[:len(p)]
+	body := s.resp.BodyStream()
+	s.reading = true
+	s.mu.Unlock()
+
+	go func() {
+		n, err := body.Read(buf)
+
+		s.mu.Lock()
+		s.reading = false
+		if s.closed {
+			_ = s.release()
+		}
+		s.mu.Unlock()
+		s.result <- bodyReadResult{n: n, err: err}
+	}()
+
+	select {
+	case result := <-s.result:
+		copy(p, buf[:result.n])
+		return result.n, result.err // nolint: wrapcheck // io.EOF must not be wrapped
+	case <-s.ctx.Done():
+		return 0, errors.WithStack(s.ctx.Err())
+	}
+}
+
+func (s *pooledBodyStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.reading {
+		// released by the in-flight read when it returns.
+		return nil
+	}
+	return s.release()
+}
+
+// release closes the body stream and releases the pooled response, the caller must hold s.mu.
+func (s *pooledBodyStream) release() error {
+	err := s.resp.CloseBodyStream()
+	fasthttp.ReleaseResponse(s.resp)
+	s.resp = nil
+	return errors.WithStack(err)
+}
@@ -0,0 +1,253 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+const streamTestSize = 1 << 20
+
+func newStreamTestServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	var count atomic.Int32
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		count.Add(1)
+		switch string(ctx.Path()) {
+		case "/upload":
+			ctx.Response.Header.Set("X-Content-Length", strconv.Itoa(ctx.Request.Header.ContentLength()))
+			ctx.Response.Header.Set("X-Content-Type", string(ctx.Request.Header.ContentType()))
+			ctx.Write(ctx.PostBody())
+		case "/download":
+			ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+				chunk := bytes.Repeat([]byte("x"), 1024)
+				for range streamTestSize / len(chunk) {
+					w.Write(chunk)
+					w.Flush()
+				}
+			})
+		case "/unavailable":
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			ctx.WriteString(strings.Repeat("e", 2*maxErrorBodySize))
+		}
+	})
+
+	return baseURL, &count
+}
+
+func TestRequestBodyStream(t *testing.T) {
+	baseURL, _ := newStreamTestServer(t)
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	body := bytes.Repeat([]byte("a"), streamTestSize)
+
+	t.Run("known size", func(t *testing.T) {
+		resp, err := client.Post(context.Background(), "/upload", RequestOptions{
+			BodyStream:     bytes.NewReader(body),
+			BodyStreamSize: len(body),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(len(body)), string(resp.Header.Peek("X-Content-Length")))
+		assert.Equal(t, "application/octet-stream", string(resp.Header.Peek("X-Content-Type")))
+		assert.Equal(t, body, resp.Body())
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := client.Post(ctx, "/upload", RequestOptions{
+			BodyStream: io.MultiReader(bytes.NewReader(body[:10]), bytes.NewReader(body[10:])),
+			Header:     map[string]string{"Content-Type": "text/plain"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain", string(resp.Header.Peek("X-Content-Type")))
+		assert.Equal(t, body, resp.Body())
+	})
+}
+
+func TestRequestBodyStreamCancelStalled(t *testing.T) {
+	baseURL, _ := newStreamTestServer(t)
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	// the body stream blocks until the test is finished.
+	body, w := io.Pipe()
+	t.Cleanup(func() { w.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.Post(ctx, "/upload", RequestOptions{BodyStream: body})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRequestBodyStreamNotRetried(t *testing.T) {
+	baseURL, count := newStreamTestServer(t)
+	client, err := New(baseURL, Config{
+		Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryNonIdempotent: true},
+	})
+	require.NoError(t, err)
+
+	resp, err := client.Post(context.Background(), "/unavailable", RequestOptions{
+		BodyStream: strings.NewReader("body"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, int32(1), count.Load())
+}
+
+func TestDoStream(t *testing.T) {
+	baseURL, _ := newStreamTestServer(t)
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for name, ctx := range map[string]context.Context{
+		"background": context.Background(),
+		"cancelable": ctx,
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := client.DoStream(ctx, fasthttp.MethodGet, "/download", RequestOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+
+			n, err := io.Copy(io.Discard, resp)
+			require.NoError(t, err)
+			assert.Equal(t, int64(streamTestSize), n)
+
+			assert.NoError(t, resp.Close())
+			assert.NoError(t, resp.Close())
+
+			_, err = resp.Read(make([]byte, 1))
+			assert.ErrorIs(t, err, ErrStreamClosed)
+			assert.True(t, errors.Is(err, errs.Closed))
+		})
+	}
+}
+
+func TestFasthttpTransportStreamRelease(t *testing.T) {
+	baseURL, _ := newStreamTestServer(t)
+	transport := NewFasthttpTransport(&fasthttp.Client{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(baseURL + "/download")
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	resp.StreamBody = true
+	require.NoError(t, transport.Do(ctx, req, resp))
+
+	// the private response copy is moved with the body stream, and released when the stream is closed.
+	stream, ok := resp.BodyStream().(*pooledBodyStream)
+	require.True(t, ok, "body stream %T", resp.BodyStream())
+	n, err := io.Copy(io.Discard, stream)
+	require.NoError(t, err)
+	assert.Equal(t, int64(streamTestSize), n)
+
+	require.NoError(t, resp.CloseBodyStream())
+	assert.Nil(t, stream.resp)
+	assert.NoError(t, stream.Close())
+}
+
+func TestDoStreamCloseStalled(t *testing.T) {
+	stall := make(chan struct{})
+	baseURL := newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+			w.WriteString("first")
+			w.Flush()
+			<-stall
+		})
+	})
+	t.Cleanup(func() { close(stall) })
+
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	// readStalled reads the first chunk, then returns the result of the next read that stalls.
+	readStalled := func(t *testing.T, resp *StreamResponse) <-chan error {
+		t.Helper()
+
+		buf := make([]byte, len("first"))
+		_, err := io.ReadFull(resp, buf)
+		require.NoError(t, err)
+		readErr := make(chan error, 1)
+		go func() {
+			_, err := resp.Read(buf)
+			readErr <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		return readErr
+	}
+
+	t.Run("close", func(t *testing.T) {
+		resp, err := client.DoStream(context.Background(), fasthttp.MethodGet, "/", RequestOptions{})
+		require.NoError(t, err)
+		readErr := readStalled(t, resp)
+
+		closed := make(chan error, 1)
+		go func() { closed <- resp.Close() }()
+		select {
+		case err := <-closed:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Close is blocked by the stalled read")
+		}
+		select {
+		case err := <-readErr:
+			assert.ErrorIs(t, err, ErrStreamClosed)
+		case <-time.After(time.Second):
+			t.Fatal("stalled read is not interrupted by Close")
+		}
+	})
+
+	t.Run("context cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resp, err := client.DoStream(ctx, fasthttp.MethodGet, "/", RequestOptions{})
+		require.NoError(t, err)
+		defer resp.Close()
+		readErr := readStalled(t, resp)
+
+		cancel()
+		select {
+		case err := <-readErr:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("stalled read is not interrupted by the context")
+		}
+	})
+}
+
+func TestDoStreamStatusError(t *testing.T) {
+	baseURL, _ := newStreamTestServer(t)
+	client, err := New(baseURL, Config{ReturnStatusError: true})
+	require.NoError(t, err)
+
+	_, err = client.DoStream(context.Background(), fasthttp.MethodGet, "/unavailable", RequestOptions{})
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Len(t, statusErr.Body, maxErrorBodySize)
+	assert.True(t, errors.Is(err, errs.Unavailable))
+}
@@ -0,0 +1,200 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// Tracer starts a span for each attempt of the requests of Client, see Config.Tracer.
+// It's a small adapter interface, e.g. for OpenTelemetry tracers.
+type Tracer interface {
+	// Start starts the span of an attempt. The parent span context of the context (see SpanContextFromContext)
+	// should be used as the parent of the span. The returned context is used for the attempt.
+	Start(ctx context.Context, info SpanInfo) (context.Context, Span)
+}
+
+// Span is a span started by Tracer.
+type Span interface {
+	// SpanContext returns the span context that is sent in the traceparent header of the attempt.
+	// The header is not sent if the span context is invalid.
+	SpanContext() SpanContext
+
+	// End ends the span with the result of the attempt.
+	End(result SpanResult)
+}
+
+// SpanInfo is the information of an attempt of a request.
+type SpanInfo struct {
+	Method string
+	URL    string
+
+	// Route is RequestOptions.Route, or the request path if it's not set.
+	Route string
+
+	// Attempt number of the request, starting from 1.
+	Attempt int
+}
+
+// SpanResult is the result of an attempt of a request.
+type SpanResult struct {
+	// StatusCode of the response, 0 if there is no response.
+	StatusCode int
+	Err        error
+	ErrorClass ErrorClass
+}
+
+// Metrics records the metrics of the requests of Client, see Config.Metrics and MetricsCollector.
+// The methods are called once per request (not per attempt), and must be thread-safe.
+type Metrics interface {
+	// RequestStarted is called before the request is sent, e.g. to increase the in-flight gauge.
+	RequestStarted(method, route string)
+
+	// RequestFinished is called when the request is finished with the same method and route as RequestStarted.
+	RequestFinished(result RequestResult)
+}
+
+// RequestResult is the result of a request including all its attempts.
+type RequestResult struct {
+	Method string
+	Route  string
+
+	// StatusCode of the response, 0 if there is no response.
+	StatusCode int
+	ErrorClass ErrorClass
+	Duration   time.Duration
+}
+
+// ErrorClass is the class of a failed request, used as a low-cardinality label of metrics and spans.
+type ErrorClass string
+
+const (
+	// ErrorClassNone means the request is successful, or the status code is not an error (1xx-3xx).
+	ErrorClassNone        ErrorClass = ""
+	ErrorClassTimeout     ErrorClass = "timeout"
+	ErrorClassCanceled    ErrorClass = "canceled"
+	ErrorClassConnection  ErrorClass = "connection"
+	ErrorClassCircuitOpen ErrorClass = "circuit_open"
+	ErrorClassRateLimited ErrorClass = "rate_limited"
+	ErrorClassClientError ErrorClass = "client_error" // 4xx status code
+	ErrorClassServerError ErrorClass = "server_error" // 5xx status code
+	ErrorClassOther       ErrorClass = "other"
+)
+
+// ClassifyError returns the error class of the result of a request.
+func ClassifyError(err error, statusCode int) ErrorClass {
+	if err == nil {
+		switch {
+		case statusCode >= fasthttp.StatusInternalServerError:
+			return ErrorClassServerError
+		case statusCode >= fasthttp.StatusBadRequest:
+			return ErrorClassClientError
+		default:
+			return ErrorClassNone
+		}
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, fasthttp.ErrTimeout), errors.Is(err, errs.Timeout):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, ErrCircuitOpen):
+		return ErrorClassCircuitOpen
+	case errors.Is(err, errs.RateLimitExceeded):
+		return ErrorClassRateLimited
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	case errors.Is(err, fasthttp.ErrConnectionClosed), errors.Is(err, fasthttp.ErrNoFreeConns),
+		errors.Is(err, fasthttp.ErrDialTimeout):
+		return ErrorClassConnection
+	default:
+		return ErrorClassOther
+	}
+}
+
+// requestRoute returns RequestOptions.Route of the request, or the request path if it's not set.
+func requestRoute(state *requestState, req *fasthttp.Request) string {
+	if state.route != "" {
+		return state.route
+	}
+	return string(req.URI().Path())
+}
+
+// traceMiddleware starts a span of each attempt with the tracer (if any), and sends the span context of the attempt
+// in the W3C traceparent header. Without tracer, the span context of the context is sent as is.
+func traceMiddleware(tracer Tracer) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			var span Span
+			sc, _ := SpanContextFromContext(ctx)
+			if tracer != nil {
+				state := requestStateFromContext(ctx)
+				ctx, span = tracer.Start(ctx, SpanInfo{
+					Method:  string(req.Header.Method()),
+					URL:     req.URI().String(),
+					Route:   requestRoute(state, req),
+					Attempt: state.attempts,
+				})
+				sc = span.SpanContext()
+			}
+			if sc.IsValid() {
+				req.Header.Set(HeaderTraceParent, sc.TraceParent())
+				if sc.TraceState != "" {
+					req.Header.Set(HeaderTraceState, sc.TraceState)
+				} else {
+					req.Header.Del(HeaderTraceState)
+				}
+			}
+
+			err := next(ctx, req, resp)
+			if span != nil {
+				statusCode := resp.StatusCode()
+				if err != nil {
+					statusCode = 0
+				}
+				span.End(SpanResult{
+					StatusCode: statusCode,
+					Err:        err,
+					ErrorClass: ClassifyError(err, statusCode),
+				})
+			}
+			return err
+		}
+	}
+}
+
+// metricsMiddleware records the in-flight requests, latency and errors of the requests.
+func metricsMiddleware(metrics Metrics) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			method := string(req.Header.Method())
+			route := requestRoute(requestStateFromContext(ctx), req)
+			metrics.RequestStarted(method, route)
+
+			start := time.Now()
+			err := next(ctx, req, resp)
+
+			statusCode := resp.StatusCode()
+			if err != nil {
+				statusCode = 0
+			}
+			metrics.RequestFinished(RequestResult{
+				Method:     method,
+				Route:      route,
+				StatusCode: statusCode,
+				ErrorClass: ClassifyError(err, statusCode),
+				Duration:   time.Since(start),
+			})
+			return err
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// newTraceTestServer returns a server that replies the traceparent header of the request,
+// the first `failures` requests are replied with 503.
+func newTraceTestServer(t *testing.T, failures int32) string {
+	t.Helper()
+
+	var count atomic.Int32
+	return newTestServer(t, func(ctx *fasthttp.RequestCtx) {
+		if count.Add(1) <= failures {
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		}
+		ctx.Write(ctx.Request.Header.Peek(HeaderTraceParent))
+	})
+}
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+type testSpan struct {
+	info   SpanInfo
+	sc     SpanContext
+	result *SpanResult
+}
+
+func (tr *testTracer) Start(ctx context.Context, info SpanInfo) (context.Context, Span) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	parent, _ := SpanContextFromContext(ctx)
+	span := &testSpan{info: info, sc: parent}
+	span.sc.SpanID = [8]byte{0, 0, 0, 0, 0, 0, 0, byte(len(tr.spans) + 1)}
+	tr.spans = append(tr.spans, span)
+	return ContextWithSpanContext(ctx, span.sc), span
+}
+
+func (s *testSpan) SpanContext() SpanContext { return s.sc }
+
+func (s *testSpan) End(result SpanResult) { s.result = &result }
+
+func TestTraceParent(t *testing.T) {
+	const value = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceParent(value)
+	require.NoError(t, err)
+	assert.True(t, sc.IsValid())
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, value, sc.TraceParent())
+
+	// future versions may have trailing fields.
+	_, err = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	assert.NoError(t, err)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+	} {
+		_, err := ParseTraceParent(invalid)
+		assert.ErrorIs(t, err, errs.InvalidArgument, invalid)
+	}
+}
+
+func TestTraceParentInjection(t *testing.T) {
+	baseURL := newTraceTestServer(t, 0)
+	client, err := New(baseURL)
+	require.NoError(t, err)
+
+	resp, err := client.Get(context.Background(), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Body())
+
+	sc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	resp, err = client.Get(ContextWithSpanContext(context.Background(), sc), "/", RequestOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, sc.TraceParent(), string(resp.Body()))
+}
+
+func TestTracer(t *testing.T) {
+	baseURL := newTraceTestServer(t, 1)
+	tracer := &testTracer{}
+	client, err := New(baseURL, Config{
+		Tracer: tracer,
+		Retry:  RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	parent, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	ctx := ContextWithSpanContext(context.Background(), parent)
+	resp, err := client.Get(ctx, "/users/1", RequestOptions{Route: "/users/{id}"})
+	require.NoError(t, err)
+
+	require.Len(t, tracer.spans, 2)
+	for i, span := range tracer.spans {
+		assert.Equal(t, SpanInfo{
+			Method:  fasthttp.MethodGet,
+			URL:     baseURL + "/users/1",
+			Route:   "/users/{id}",
+			Attempt: i + 1,
+		}, span.info)
+		assert.Equal(t, parent.TraceID, span.sc.TraceID)
+		require.NotNil(t, span.result)
+	}
+	assert.Equal(t, SpanResult{StatusCode: 503, ErrorClass: ErrorClassServerError}, *tracer.spans[0].result)
+	assert.Equal(t, SpanResult{StatusCode: 200}, *tracer.spans[1].result)
+
+	// the traceparent of the last attempt is the span of the attempt, not the parent.
+	assert.Equal(t, tracer.spans[1].sc.TraceParent(), string(resp.Body()))
+}
+
+func TestMetricsCollector(t *testing.T) {
+	baseURL := newTraceTestServer(t, 1)
+	metrics := NewMetricsCollector(0.5, 0.1)
+	client, err := New(baseURL, Config{Metrics: metrics})
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := client.Get(context.Background(), "/users/1", RequestOptions{Route: "/users/{id}"})
+		require.NoError(t, err)
+	}
+	unreachable, err := New("http://127.0.0.1:1", Config{Metrics: metrics})
+	require.NoError(t, err)
+	_, err = unreachable.Post(context.Background(), "/", RequestOptions{})
+	require.Error(t, err)
+
+	var buf bytes.Buffer
+	_, err = metrics.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE httpclient_requests_in_flight gauge\n")
+	assert.Contains(t, out, `httpclient_requests_in_flight{method="GET",route="/users/{id}"} 0`+"\n")
+	assert.Contains(t, out, "# TYPE httpclient_request_duration_seconds histogram\n")
+	assert.Contains(t, out, `httpclient_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="200",le="0.5"} 1`+"\n")
+	assert.Contains(t, out, `httpclient_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="503",le="+Inf"} 1`+"\n")
+	assert.Contains(t, out, `httpclient_request_duration_seconds_count{method="POST",route="/",status="0"} 1`+"\n")
+	assert.Contains(t, out, `httpclient_request_errors_total{method="GET",route="/users/{id}",class="server_error"} 1`+"\n")
+	assert.Contains(t, out, `httpclient_request_errors_total{method="POST",route="/",class="connection"} 1`+"\n")
+
+	// buckets are sorted.
+	assert.Less(t, strings.Index(out, `le="0.1"`), strings.Index(out, `le="0.5"`))
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, out, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
+
+func TestMetricsCollectorInFlight(t *testing.T) {
+	metrics := NewMetricsCollector()
+	metrics.RequestStarted(fasthttp.MethodGet, `/a"b`)
+
+	var buf bytes.Buffer
+	_, err := metrics.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `httpclient_requests_in_flight{method="GET",route="/a\"b"} 1`+"\n")
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		want       ErrorClass
+	}{
+		{name: "ok", statusCode: 200, want: ErrorClassNone},
+		{name: "redirect", statusCode: 302, want: ErrorClassNone},
+		{name: "client error", statusCode: 404, want: ErrorClassClientError},
+		{name: "server error", statusCode: 502, want: ErrorClassServerError},
+		{name: "deadline", err: errors.WithStack(context.DeadlineExceeded), want: ErrorClassTimeout},
+		{name: "fasthttp timeout", err: fasthttp.ErrTimeout, want: ErrorClassTimeout},
+		{name: "canceled", err: errors.Wrap(context.Canceled, "request"), want: ErrorClassCanceled},
+		{name: "circuit open", err: errors.WithStack(ErrCircuitOpen), want: ErrorClassCircuitOpen},
+		{name: "rate limited", err: errors.WithStack(errs.RateLimitExceeded), want: ErrorClassRateLimited},
+		{name: "connection", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: ErrorClassConnection},
+		{name: "connection closed", err: fasthttp.ErrConnectionClosed, want: ErrorClassConnection},
+		{name: "other", err: errors.New("boom"), want: ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err, tt.statusCode))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/cockroachdb/errors"
+)
+
+// W3C trace context headers, see https://www.w3.org/TR/trace-context/.
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+)
+
+const (
+	traceParentVersion = "00"
+	traceParentSize    = 55 // version(2) - trace id(32) - parent id(16) - flags(2)
+)
+
+// TraceFlagsSampled is the sampled flag of the trace flags.
+const TraceFlagsSampled byte = 0x01
+
+// SpanContext identifies a span of a trace, it's propagated to the server in the W3C traceparent and tracestate headers.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+	TraceState string
+}
+
+// IsValid returns true if both trace id and span id are not all zeros.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// IsSampled returns true if the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags&TraceFlagsSampled != 0
+}
+
+// TraceParent returns the traceparent header value of the span context.
+func (sc SpanContext) TraceParent() string {
+	buf := make([]byte, 0, traceParentSize)
+	buf = append(buf, traceParentVersion...)
+	buf = append(buf, '-')
+	buf = hex.AppendEncode(buf, sc.TraceID[:])
+	buf = append(buf, '-')
+	buf = hex.AppendEncode(buf, sc.SpanID[:])
+	buf = append(buf, '-')
+	buf = hex.AppendEncode(buf, []byte{sc.TraceFlags})
+	return string(buf)
+}
+
+// ParseTraceParent parses the traceparent header value, e.g. to continue the trace of an incoming request.
+// The tracestate is not part of the traceparent, it should be set to TraceState of the result separately.
+func ParseTraceParent(value string) (SpanContext, error) {
+	var sc SpanContext
+	if len(value) < traceParentSize || (len(value) > traceParentSize && value[traceParentSize] != '-') {
+		return sc, errors.Wrapf(errs.InvalidArgument, "invalid traceparent: %q", value)
+	}
+	version, err := hex.DecodeString(value[0:2])
+	if err != nil || version[0] == 0xff || value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return sc, errors.Wrapf(errs.InvalidArgument, "invalid traceparent: %q", value)
+	}
+	// version 00 has no trailing fields, later versions may add fields after the flags.
+	if value[0:2] == traceParentVersion && len(value) != traceParentSize {
+		return sc, errors.Wrapf(errs.InvalidArgument, "invalid traceparent: %q", value)
+	}
+
+	var flags [1]byte
+	if _, err := hex.Decode(sc.TraceID[:], []byte(value[3:35])); err != nil {
+		return sc, errors.Wrapf(errs.InvalidArgument, "invalid traceparent trace id: %q", value)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(value[36:52])); err != nil {
+		return sc, errors.Wrapf(errs.InvalidArgument, "invalid traceparent parent id: %q", value)
+	}
+	if _, err := hex.Decode(flags[:], []byte(value[53:55])); err != nil {
+		return sc, errors.Wrapf(errs.InvalidArgument, "invalid traceparent flags: %q", value)
+	}
+	sc.TraceFlags = flags[0]
+	if !sc.IsValid() {
+		return sc, errors.Wrapf(errs.InvalidArgument, "invalid traceparent: %q", value)
+	}
+	return sc, nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of the context with the span context.
+// The span context is sent in the traceparent header of the requests with the context, unless Config.Tracer starts
+// a child span for the request.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the context, or false if it's not found or invalid.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
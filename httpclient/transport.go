@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// Transport sends the request and fills the response, it's the last step of the round trip after all middlewares.
+//
+// The request and response are owned by the caller, the transport MUST NOT use them after Do returns.
+type Transport interface {
+	Do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error
+}
+
+// defaultBackgroundTimeout is the timeout of requests with a cancellable context without deadline,
+// so a cancelled request doesn't keep running in the background until a hung server answers.
+const defaultBackgroundTimeout = time.Minute
+
+// NewFasthttpTransport returns a transport that sends requests with the fasthttp client.
+//
+// Requests with a cancellable context without deadline time out after 1 minute, unless the fasthttp client has
+// a ReadTimeout. Streamed responses are not limited, their body is read after the request returns.
+func NewFasthttpTransport(client *fasthttp.Client) Transport {
+	return &fasthttpTransport{client: client, backgroundTimeout: defaultBackgroundTimeout}
+}
+
+// fasthttpTransport sends requests with a fasthttp client.
+type fasthttpTransport struct {
+	client            *fasthttp.Client
+	backgroundTimeout time.Duration
+}
+
+// Do sends the request and waits for the response or until the context is done.
+//
+// The context deadline is applied to the fasthttp request with DoDeadline, so the connection is not held after the deadline.
+// If the context can be cancelled, the request is sent with a private copy of req/resp, so the caller can
+// release them as soon as this method returns, even if the fasthttp client is still using the copies in the background.
+// The copies are released when the background request is finished. The request body stream is not read anymore
+// after this method returns, except an in-flight Read that can't be interrupted.
+func (t *fasthttpTransport) Do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	deadline, hasDeadline := ctx.Deadline()
+
+	// context can't be cancelled, send the request synchronously without copying.
+	if ctx.Done() == nil {
+		return errors.WithStack(t.send(req, resp, deadline, hasDeadline))
+	}
+	if err := ctx.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the request keeps running in the background after the context is cancelled, bound it.
+	sendDeadline, hasSendDeadline := deadline, hasDeadline
+	if !hasDeadline && !resp.StreamBody && t.client.ReadTimeout <= 0 && t.backgroundTimeout > 0 {
+		sendDeadline, hasSendDeadline = time.Now().Add(t.backgroundTimeout), true
+	}
+
+	reqCopy := fasthttp.AcquireRequest()
+	respCopy := fasthttp.AcquireResponse()
+	req.CopyTo(reqCopy)
+	respCopy.StreamBody = resp.StreamBody
+	release := func() {
+		fasthttp.ReleaseRequest(reqCopy)
+		fasthttp.ReleaseResponse(respCopy)
+	}
+
+	// the body stream isn't copied by CopyTo, share it through a guard that stops reading when the context is done.
+	var bodyStream *guardedReader
+	if req.IsBodyStream() {
+		bodyStream = &guardedReader{r: req.BodyStream()}
+		reqCopy.SetBodyStream(bodyStream, req.Header.ContentLength())
+	}
+
+	resultCh := make(chan error, 1)
+	go func() {
+		resultCh <- t.send(reqCopy, respCopy, sendDeadline, hasSendDeadline)
+	}()
+
+	select {
+	case <-ctx.Done():
+		// the background request still owns the copies, release them when it's finished.
+		go func() {
+			<-resultCh
+			release()
+		}()
+		if bodyStream != nil {
+			bodyStream.abort(ctx.Err())
+		}
+		return errors.WithStack(ctx.Err())
+	case err := <-resultCh:
+		if err == nil && respCopy.IsBodyStream() {
+			// move the body stream to resp, respCopy owns the stream buffer so it's released when the stream is closed.
+			fasthttp.ReleaseRequest(reqCopy)
+			respCopy.Header.CopyTo(&resp.Header)
+			resp.SetBodyStream(newPooledBodyStream(ctx, respCopy), respCopy.Header.ContentLength())
+			return nil
+		}
+		defer release()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return errors.WithStack(ctxErr)
+			}
+			// fasthttp may time out at the deadline slightly before the context timer fires.
+			if hasDeadline && !time.Now().Before(deadline) {
+				return errors.Wrap(context.DeadlineExceeded, err.Error())
+			}
+			return errors.WithStack(err)
+		}
+		respCopy.CopyTo(resp)
+		return nil
+	}
+}
+
+func (t *fasthttpTransport) send(req *fasthttp.Request, resp *fasthttp.Response, deadline time.Time, hasDeadline bool) error {
+	if hasDeadline {
+		return t.client.DoDeadline(req, resp, deadline)
+	}
+	return t.client.Do(req, resp)
+}
@@ -0,0 +1,141 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// EnqueueMany adds items to the end of the queue in order with a single lock acquisition.
+// returns the number of enqueued items.
+//
+// If the queue is bounded and full, the overflow policy is applied to each item (see OverflowPolicy).
+func (q *Queue[T]) EnqueueMany(items ...T) (count int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, item := range items {
+		index, err := q.enqueueLocked(context.Background(), item)
+		if errors.Is(err, ErrClosed) {
+			break
+		}
+		if index >= 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+// DequeueN removes up to n items from the front of the queue.
+//
+// If the queue is empty, this method blocks(wait) until at least one item is available.
+//
+// returns second value as false if the queue is closed.
+//
+// panics if n is not positive.
+func (q *Queue[T]) DequeueN(n int) (items []T, ok bool) {
+	if n <= 0 {
+		panic("queue: n must be positive")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.items.len() == 0 {
+		if q.isClosed {
+			return nil, false
+		}
+		q.cond.Wait()
+	}
+
+	if q.isClosed {
+		return nil, false
+	}
+
+	return q.popLocked(n, nil), true
+}
+
+// DequeueBatch removes up to maxSize items from the front of the queue.
+//
+// This method blocks(wait) until at least one item is available or the context is done,
+// then lingers up to maxWait for more items to fill the batch. It returns as soon as the batch is full.
+//
+// returns ctx.Err() if the context is done before any item is available, or ErrClosed if the queue is closed.
+// If the context is done or the queue is closed while lingering, the collected items are returned without error.
+//
+// panics if maxSize is not positive.
+func (q *Queue[T]) DequeueBatch(ctx context.Context, maxSize int, maxWait time.Duration) (items []T, err error) {
+	if maxSize <= 0 {
+		panic("queue: maxSize must be positive")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.items.len() == 0 {
+		if q.isClosed {
+			return nil, ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		q.cond.Wait()
+	}
+
+	if q.isClosed {
+		return nil, ErrClosed
+	}
+
+	items = q.popLocked(maxSize, make([]T, 0, min(maxSize, q.items.len())))
+	if len(items) >= maxSize || maxWait <= 0 {
+		return items, nil
+	}
+
+	lingering := true
+	timer := time.AfterFunc(maxWait, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		lingering = false
+		q.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	for len(items) < maxSize && lingering && !q.isClosed && ctx.Err() == nil {
+		if q.items.len() == 0 {
+			q.cond.Wait()
+			continue
+		}
+		items = q.popLocked(maxSize-len(items), items)
+	}
+
+	// pass the wake-up signal on to another waiter if this waiter left items in the queue.
+	if q.items.len() > 0 {
+		q.cond.Signal()
+	}
+
+	return items, nil
+}
+
+// popLocked removes up to n items from the front of the queue and appends them to dst. the caller must hold the lock.
+func (q *Queue[T]) popLocked(n int, dst []T) []T {
+	n = min(n, q.items.len())
+	if dst == nil {
+		dst = make([]T, 0, n)
+	}
+	for i := 0; i < n; i++ {
+		dst = append(dst, q.items.pop())
+	}
+	q.removedLocked(n)
+	return dst
+}
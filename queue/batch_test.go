@@ -0,0 +1,154 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnqueueMany(t *testing.T) {
+	q := New[int]()
+	if count := q.EnqueueMany(1, 2, 3); count != 3 {
+		t.Errorf("enqueued count should be equal to the expected value, expected: %d, actual: %d", 3, count)
+	}
+	if expected, actual := []int{1, 2, 3}, q.Items(); !equalItems(expected, actual) {
+		t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", expected, actual)
+	}
+
+	bounded := NewBounded[int](2, OverflowDropNewest)
+	if count := bounded.EnqueueMany(1, 2, 3); count != 2 {
+		t.Errorf("enqueued count should be equal to the expected value, expected: %d, actual: %d", 2, count)
+	}
+
+	q.Close()
+	if count := q.EnqueueMany(4, 5); count != 0 {
+		t.Errorf("should not enqueue to a closed queue")
+	}
+}
+
+func TestDequeueN(t *testing.T) {
+	q := New[int]()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		q.EnqueueMany(1, 2, 3, 4, 5)
+	}()
+
+	// expected to block until enqueue
+	items, ok := q.DequeueN(3)
+	if !ok {
+		t.Errorf("items should be dequeued")
+	}
+	if len(items) < 1 || len(items) > 3 {
+		t.Errorf("dequeued items should be between 1 and 3, actual: %v", items)
+	}
+
+	items, ok = q.DequeueN(10)
+	if !ok || len(items) == 0 {
+		t.Errorf("remaining items should be dequeued, actual: %v", items)
+	}
+
+	q.Close()
+	if _, ok := q.DequeueN(1); ok {
+		t.Errorf("should not dequeue from a closed queue")
+	}
+}
+
+func TestDequeueBatch(t *testing.T) {
+	t.Run("full batch", func(t *testing.T) {
+		q := New[int]()
+		q.EnqueueMany(1, 2, 3, 4, 5)
+
+		start := time.Now()
+		items, err := q.DequeueBatch(context.Background(), 3, time.Second)
+		if err != nil {
+			t.Errorf("items should be dequeued, got error: %v", err)
+		}
+		if expected := []int{1, 2, 3}; !equalItems(expected, items) {
+			t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", expected, items)
+		}
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("should not linger when the batch is full, elapsed: %v", elapsed)
+		}
+	})
+
+	t.Run("linger", func(t *testing.T) {
+		q := New[int]()
+		go func() {
+			q.Enqueue(1)
+			time.Sleep(50 * time.Millisecond)
+			q.Enqueue(2)
+		}()
+
+		start := time.Now()
+		items, err := q.DequeueBatch(context.Background(), 10, 200*time.Millisecond)
+		if err != nil {
+			t.Errorf("items should be dequeued, got error: %v", err)
+		}
+		if expected := []int{1, 2}; !equalItems(expected, items) {
+			t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", expected, items)
+		}
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("should linger until maxWait, elapsed: %v", elapsed)
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		q := New[int]()
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		if _, err := q.DequeueBatch(ctx, 10, time.Second); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error should be context.DeadlineExceeded, actual: %v", err)
+		}
+	})
+
+	t.Run("closed while lingering", func(t *testing.T) {
+		q := New[int]()
+		q.Enqueue(1)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			q.Close()
+		}()
+
+		items, err := q.DequeueBatch(context.Background(), 10, time.Second)
+		if err != nil {
+			t.Errorf("collected items should be returned without error, got error: %v", err)
+		}
+		if expected := []int{1}; !equalItems(expected, items) {
+			t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", expected, items)
+		}
+		if _, err := q.DequeueBatch(context.Background(), 10, time.Second); !errors.Is(err, ErrClosed) {
+			t.Errorf("error should be ErrClosed, actual: %v", err)
+		}
+	})
+}
+
+func TestDequeueInvalidSize(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		q := New[int]()
+		q.Enqueue(1)
+
+		t.Run("DequeueN", func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != "queue: n must be positive" {
+					t.Errorf("should panic with non-positive n %d, actual: %v", n, r)
+				}
+			}()
+			q.DequeueN(n)
+		})
+
+		t.Run("DequeueBatch", func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != "queue: maxSize must be positive" {
+					t.Errorf("should panic with non-positive maxSize %d, actual: %v", n, r)
+				}
+			}()
+			q.DequeueBatch(context.Background(), n, time.Second)
+		})
+
+		if q.Len() != 1 {
+			t.Errorf("queue should not be modified, len: %d", q.Len())
+		}
+	}
+}
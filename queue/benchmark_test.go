@@ -0,0 +1,152 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+)
+
+// sliceQueue is the previous slice-based storage of Queue, kept for benchmark comparison.
+type sliceQueue[T any] struct {
+	mu    sync.Mutex
+	items []T
+}
+
+func (q *sliceQueue[T]) enqueue(item T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = append(q.items, item)
+}
+
+func (q *sliceQueue[T]) dequeue() (val T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return val, false
+	}
+	val = q.items[0]
+	q.items = q.items[1:]
+	return val, true
+}
+
+// steady throughput: the queue holds a small number of items while items flow through it.
+func BenchmarkSteadyThroughput(b *testing.B) {
+	const backlog = 64
+
+	b.Run("Queue", func(b *testing.B) {
+		q := New[int]()
+		for i := 0; i < backlog; i++ {
+			q.Enqueue(i)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Enqueue(i)
+			q.TryDequeue()
+		}
+	})
+
+	b.Run("Slice", func(b *testing.B) {
+		q := &sliceQueue[int]{}
+		for i := 0; i < backlog; i++ {
+			q.enqueue(i)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.enqueue(i)
+			q.dequeue()
+		}
+	})
+
+	b.Run("Channel", func(b *testing.B) {
+		ch := make(chan int, backlog+1)
+		for i := 0; i < backlog; i++ {
+			ch <- i
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ch <- i
+			<-ch
+		}
+	})
+}
+
+// burst: enqueue a batch of items then drain all of them.
+func BenchmarkBurst(b *testing.B) {
+	const burst = 1024
+
+	b.Run("Queue", func(b *testing.B) {
+		q := New[int]()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < burst; j++ {
+				q.Enqueue(j)
+			}
+			for j := 0; j < burst; j++ {
+				q.TryDequeue()
+			}
+		}
+	})
+
+	b.Run("Slice", func(b *testing.B) {
+		q := &sliceQueue[int]{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < burst; j++ {
+				q.enqueue(j)
+			}
+			for j := 0; j < burst; j++ {
+				q.dequeue()
+			}
+		}
+	})
+
+	b.Run("Channel", func(b *testing.B) {
+		ch := make(chan int, burst)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < burst; j++ {
+				ch <- j
+			}
+			for j := 0; j < burst; j++ {
+				<-ch
+			}
+		}
+	})
+}
+
+// producer-consumer: one producer and one consumer goroutines with blocking dequeue.
+func BenchmarkProducerConsumer(b *testing.B) {
+	b.Run("Queue", func(b *testing.B) {
+		q := New[int]()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < b.N; i++ {
+				q.Dequeue()
+			}
+		}()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			q.Enqueue(i)
+		}
+		<-done
+	})
+
+	b.Run("Channel", func(b *testing.B) {
+		ch := make(chan int, 1024)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < b.N; i++ {
+				<-ch
+			}
+		}()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ch <- i
+		}
+		<-done
+	})
+}
@@ -0,0 +1,41 @@
+package queue
+
+// OverflowPolicy defines the behavior of a bounded queue when enqueue to a full queue.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks(wait) the producer until space is available.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropNewest discards the item being enqueued.
+	OverflowDropNewest
+
+	// OverflowDropOldest discards the item at the front of the queue to make space for the new item.
+	OverflowDropOldest
+
+	// OverflowError rejects the item being enqueued, EnqueueContext returns ErrFull.
+	OverflowError
+)
+
+// NewBounded creates a new message queue with limited capacity.
+// The overflow policy is applied when enqueue to a full queue.
+//
+// panics if capacity is not positive.
+func NewBounded[T comparable](capacity int, policy OverflowPolicy) *Queue[T] {
+	if capacity <= 0 {
+		panic("queue: capacity must be positive")
+	}
+	return newQueue[T](capacity, policy)
+}
+
+// Cap returns the capacity of the queue. returns 0 if the queue is unlimited-size.
+func (q *Queue[T]) Cap() int {
+	return q.capacity
+}
+
+// IsFull returns true if the queue is bounded and reaches its capacity.
+func (q *Queue[T]) IsFull() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.capacity > 0 && q.items.len() >= q.capacity
+}
@@ -0,0 +1,175 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBoundedBlock(t *testing.T) {
+	q := NewBounded[int](2, OverflowBlock)
+	q.Enqueue(1)
+	q.Enqueue(2)
+
+	if !q.IsFull() {
+		t.Errorf("queue should be full")
+	}
+
+	done := make(chan int)
+	go func() {
+		// expected to block until dequeue
+		done <- q.Enqueue(3)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("enqueue should block when the queue is full")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if item, ok := q.Dequeue(); !ok || item != 1 {
+		t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", 1, item)
+	}
+	if index := <-done; index != 1 {
+		t.Errorf("index should be equal to the expected value, expected: %d, actual: %d", 1, index)
+	}
+	if expected, actual := []int{2, 3}, q.Items(); !equalItems(expected, actual) {
+		t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestBoundedBlockContext(t *testing.T) {
+	q := NewBounded[int](1, OverflowBlock)
+	q.Enqueue(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	index, err := q.EnqueueContext(ctx, 2)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should be context.DeadlineExceeded, actual: %v", err)
+	}
+	if index != -1 {
+		t.Errorf("index should be -1, actual: %d", index)
+	}
+}
+
+func TestBoundedBlockClose(t *testing.T) {
+	q := NewBounded[int](1, OverflowBlock)
+	q.Enqueue(1)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		q.Close()
+	}()
+
+	if _, err := q.EnqueueContext(context.Background(), 2); !errors.Is(err, ErrClosed) {
+		t.Errorf("error should be ErrClosed, actual: %v", err)
+	}
+}
+
+func TestBoundedOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        OverflowPolicy
+		expectedIndex int
+		expectedErr   error
+		expectedItems []int
+	}{
+		{
+			name:          "drop newest",
+			policy:        OverflowDropNewest,
+			expectedIndex: -1,
+			expectedItems: []int{1, 2, 3},
+		},
+		{
+			name:          "drop oldest",
+			policy:        OverflowDropOldest,
+			expectedIndex: 2,
+			expectedItems: []int{2, 3, 4},
+		},
+		{
+			name:          "error",
+			policy:        OverflowError,
+			expectedIndex: -1,
+			expectedErr:   ErrFull,
+			expectedItems: []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewBounded[int](3, tt.policy)
+			for i := 1; i <= 3; i++ {
+				q.Enqueue(i)
+			}
+
+			index, err := q.EnqueueContext(context.Background(), 4)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("error should be equal to the expected value, expected: %v, actual: %v", tt.expectedErr, err)
+			}
+			if tt.expectedIndex != index {
+				t.Errorf("index should be equal to the expected value, expected: %d, actual: %d", tt.expectedIndex, index)
+			}
+			if actual := q.Items(); !equalItems(tt.expectedItems, actual) {
+				t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", tt.expectedItems, actual)
+			}
+		})
+	}
+}
+
+func TestBoundedConcurrency(t *testing.T) {
+	var (
+		q        = NewBounded[int](4, OverflowBlock)
+		wg       sync.WaitGroup
+		size     = 1000
+		received = 0
+	)
+
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < size/4; i++ {
+				q.Enqueue(i)
+				if q.Len() > q.Cap() {
+					t.Errorf("queue size should not exceed capacity")
+				}
+			}
+		}()
+	}
+
+	for received < size {
+		if _, ok := q.Dequeue(); ok {
+			received++
+		}
+	}
+	wg.Wait()
+
+	if !q.IsEmpty() {
+		t.Errorf("queue should be empty")
+	}
+}
+
+func TestNewBoundedInvalidCapacity(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("should panic with non-positive capacity")
+		}
+	}()
+	NewBounded[int](0, OverflowBlock)
+}
+
+func equalItems[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package queue
+
+import (
+	"sync"
+)
+
+// Broadcaster a thread-safe fan-out publisher built on top of Queue.
+// Each subscriber gets its own unlimited-size Queue and receives every published item.
+type Broadcaster[T comparable] struct {
+	mu          sync.RWMutex
+	subscribers map[*Queue[T]]struct{}
+	isClosed    bool
+}
+
+// NewBroadcaster creates a new broadcaster.
+func NewBroadcaster[T comparable]() *Broadcaster[T] {
+	return &Broadcaster[T]{
+		subscribers: make(map[*Queue[T]]struct{}),
+	}
+}
+
+// Subscribe creates a new subscriber queue that receives all items published after this method is called.
+//
+// The subscriber can stop receiving items by calling Unsubscribe or closing the returned queue.
+// returns a closed queue if the broadcaster is closed.
+func (b *Broadcaster[T]) Subscribe() *Queue[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := New[T]()
+	if b.isClosed {
+		sub.Close()
+		return sub
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes and closes the subscriber queue.
+func (b *Broadcaster[T]) Unsubscribe(sub *Queue[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, sub)
+	sub.Close()
+}
+
+// Publish enqueues the item to all subscribers. returns the number of subscribers that received the item.
+//
+// Closed subscriber queues are removed automatically.
+func (b *Broadcaster[T]) Publish(item T) (count int) {
+	b.mu.RLock()
+	var closed []*Queue[T]
+	for sub := range b.subscribers {
+		if sub.Enqueue(item) < 0 {
+			closed = append(closed, sub)
+			continue
+		}
+		count++
+	}
+	b.mu.RUnlock()
+
+	if len(closed) > 0 {
+		b.mu.Lock()
+		for _, sub := range closed {
+			delete(b.subscribers, sub)
+		}
+		b.mu.Unlock()
+	}
+
+	return count
+}
+
+// Len returns the number of subscribers.
+func (b *Broadcaster[T]) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// Close closes the broadcaster and all subscriber queues.
+// broadcaster will be permanent unusable after this method is called.
+func (b *Broadcaster[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.isClosed = true
+	for sub := range b.subscribers {
+		sub.Close()
+	}
+	b.subscribers = make(map[*Queue[T]]struct{})
+}
+
+// IsClosed returns true if the broadcaster is closed.
+func (b *Broadcaster[T]) IsClosed() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.isClosed
+}
+
+// Topics a thread-safe multiple topics publisher built on top of Broadcaster.
+// Each subscriber of a topic gets its own unlimited-size Queue and receives every item published to that topic.
+type Topics[K comparable, T comparable] struct {
+	mu       sync.RWMutex
+	topics   map[K]*Broadcaster[T]
+	isClosed bool
+}
+
+// NewTopics creates a new multiple topics publisher.
+func NewTopics[K comparable, T comparable]() *Topics[K, T] {
+	return &Topics[K, T]{
+		topics: make(map[K]*Broadcaster[T]),
+	}
+}
+
+// Subscribe creates a new subscriber queue for the topic. see Broadcaster.Subscribe.
+func (t *Topics[K, T]) Subscribe(topic K) *Queue[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.isClosed {
+		sub := New[T]()
+		sub.Close()
+		return sub
+	}
+
+	b, ok := t.topics[topic]
+	if !ok {
+		b = NewBroadcaster[T]()
+		t.topics[topic] = b
+	}
+	return b.Subscribe()
+}
+
+// Unsubscribe removes and closes the subscriber queue from the topic.
+// The topic is removed when it has no subscribers left.
+func (t *Topics[K, T]) Unsubscribe(topic K, sub *Queue[T]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.topics[topic]
+	if !ok {
+		sub.Close()
+		return
+	}
+	b.Unsubscribe(sub)
+	if b.Len() == 0 {
+		delete(t.topics, topic)
+	}
+}
+
+// Publish enqueues the item to all subscribers of the topic. returns the number of subscribers that received the item.
+//
+// The topic is removed when all of its subscriber queues are closed.
+func (t *Topics[K, T]) Publish(topic K, item T) (count int) {
+	t.mu.RLock()
+	b, ok := t.topics[topic]
+	t.mu.RUnlock()
+
+	if !ok {
+		return 0
+	}
+	count = b.Publish(item)
+
+	if b.Len() == 0 {
+		// re-check under the write lock, Subscribe may have added a subscriber meanwhile.
+		t.mu.Lock()
+		if t.topics[topic] == b && b.Len() == 0 {
+			delete(t.topics, topic)
+		}
+		t.mu.Unlock()
+	}
+	return count
+}
+
+// Topics returns the all topics that have subscribers.
+func (t *Topics[K, T]) Topics() []K {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	topics := make([]K, 0, len(t.topics))
+	for topic := range t.topics {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// Len returns the number of subscribers of the topic.
+func (t *Topics[K, T]) Len(topic K) int {
+	t.mu.RLock()
+	b, ok := t.topics[topic]
+	t.mu.RUnlock()
+
+	if !ok {
+		return 0
+	}
+	return b.Len()
+}
+
+// Close closes all topics and subscriber queues.
+// topics will be permanent unusable after this method is called.
+func (t *Topics[K, T]) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.isClosed = true
+	for _, b := range t.topics {
+		b.Close()
+	}
+	t.topics = make(map[K]*Broadcaster[T])
+}
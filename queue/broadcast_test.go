@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBroadcasterPublish(t *testing.T) {
+	b := NewBroadcaster[string]()
+	sub1 := b.Subscribe()
+	sub2 := b.Subscribe()
+
+	if count := b.Publish("A"); count != 2 {
+		t.Errorf("published count should be equal to the expected value, expected: %d, actual: %d", 2, count)
+	}
+
+	for _, sub := range []*Queue[string]{sub1, sub2} {
+		if item, ok := sub.Dequeue(); !ok || item != "A" {
+			t.Errorf("item should be equal to the expected value, expected: %s, actual: %s", "A", item)
+		}
+	}
+
+	b.Unsubscribe(sub1)
+	if !sub1.IsClosed() {
+		t.Errorf("unsubscribed queue should be closed")
+	}
+
+	// closed subscriber should be removed on publish.
+	sub2.Close()
+	if count := b.Publish("B"); count != 0 {
+		t.Errorf("published count should be zero, actual: %d", count)
+	}
+	if b.Len() != 0 {
+		t.Errorf("closed subscribers should be removed, actual: %d", b.Len())
+	}
+}
+
+func TestBroadcasterClose(t *testing.T) {
+	var (
+		b  = NewBroadcaster[int]()
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 4; i++ {
+		sub := b.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, ok := sub.Dequeue(); !ok {
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		b.Publish(i)
+	}
+	b.Close()
+	wg.Wait()
+
+	if sub := b.Subscribe(); !sub.IsClosed() {
+		t.Errorf("subscribe to a closed broadcaster should return a closed queue")
+	}
+}
+
+func TestTopics(t *testing.T) {
+	topics := NewTopics[string, int]()
+	blocks := topics.Subscribe("blocks")
+	txs := topics.Subscribe("txs")
+
+	topics.Publish("blocks", 1)
+	topics.Publish("txs", 2)
+	if count := topics.Publish("unknown", 3); count != 0 {
+		t.Errorf("published count should be zero, actual: %d", count)
+	}
+
+	if item, ok := blocks.TryDequeue(); !ok || item != 1 {
+		t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", 1, item)
+	}
+	if item, ok := txs.TryDequeue(); !ok || item != 2 {
+		t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", 2, item)
+	}
+	if !blocks.IsEmpty() || !txs.IsEmpty() {
+		t.Errorf("items should not be published to other topics")
+	}
+
+	topics.Unsubscribe("blocks", blocks)
+	if topics.Len("blocks") != 0 || len(topics.Topics()) != 1 {
+		t.Errorf("topic should be removed when it has no subscribers, actual: %v", topics.Topics())
+	}
+
+	topics.Close()
+	if !txs.IsClosed() {
+		t.Errorf("subscriber queue should be closed")
+	}
+}
+
+func TestTopicsClosedSubscriber(t *testing.T) {
+	topics := NewTopics[string, int]()
+	sub := topics.Subscribe("a")
+	sub.Close()
+
+	if count := topics.Publish("a", 1); count != 0 {
+		t.Errorf("published count should be zero, actual: %d", count)
+	}
+	if got := topics.Topics(); len(got) != 0 {
+		t.Errorf("topic should be removed when all subscribers are closed, actual: %v", got)
+	}
+
+	// the topic is created again by a new subscriber.
+	sub = topics.Subscribe("a")
+	if count := topics.Publish("a", 2); count != 1 {
+		t.Errorf("published count should be 1, actual: %d", count)
+	}
+	if item, ok := sub.TryDequeue(); !ok || item != 2 {
+		t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", 2, item)
+	}
+}
@@ -0,0 +1,211 @@
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue a instance of thread-safe and unlimited-size generics in-memory delay queue.
+// Each item is enqueued with a ready-at time and can be dequeued only after that time has passed.
+// Items are dequeued in order of their ready-at time, and in enqueue order for the same ready-at time.
+type DelayQueue[T any] struct {
+	cond     *sync.Cond
+	items    *priorityItems[delayedItem[T]]
+	seq      uint64
+	mu       sync.RWMutex
+	isClosed bool
+}
+
+type delayedItem[T any] struct {
+	value   T
+	readyAt time.Time
+	seq     uint64
+}
+
+// NewDelay creates a new delay queue.
+func NewDelay[T any]() *DelayQueue[T] {
+	q := &DelayQueue[T]{
+		items: &priorityItems[delayedItem[T]]{
+			items: make([]delayedItem[T], 0),
+			less: func(a, b delayedItem[T]) bool {
+				if a.readyAt.Equal(b.readyAt) {
+					return a.seq < b.seq
+				}
+				return a.readyAt.Before(b.readyAt)
+			},
+		},
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Close closes the queue and discards all pending items.
+// queue will be permanent unusable after this method is called.
+func (q *DelayQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.isClosed = true
+	q.items.items = nil
+	q.cond.Broadcast()
+}
+
+// IsClosed returns true if the queue is closed.
+func (q *DelayQueue[T]) IsClosed() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.isClosed
+}
+
+// Enqueue adds an item that is ready to be dequeued at the given time. returns false if the queue is closed.
+func (q *DelayQueue[T]) Enqueue(item T, readyAt time.Time) (ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.isClosed {
+		return false
+	}
+
+	q.seq++
+	heap.Push(q.items, delayedItem[T]{value: item, readyAt: readyAt, seq: q.seq})
+
+	// wake up all waiters to recalculate their sleep duration, the new item might be ready earlier.
+	q.cond.Broadcast()
+
+	return true
+}
+
+// EnqueueAfter adds an item that is ready to be dequeued after the given delay. returns false if the queue is closed.
+func (q *DelayQueue[T]) EnqueueAfter(item T, delay time.Duration) (ok bool) {
+	return q.Enqueue(item, time.Now().Add(delay))
+}
+
+// Dequeue removes the earliest ready item from the queue.
+//
+// If no item is ready, this method blocks(wait) until the earliest item is ready.
+//
+// returns second value as false if the queue is closed.
+func (q *DelayQueue[T]) Dequeue() (val T, ok bool) {
+	val, err := q.DequeueContext(context.Background())
+	return val, err == nil
+}
+
+// DequeueContext removes the earliest ready item from the queue, similar to Dequeue.
+//
+// If no item is ready, this method blocks(wait) until the earliest item is ready or the context is done.
+//
+// returns ctx.Err() if the context is done before an item is ready, or ErrClosed if the queue is closed.
+func (q *DelayQueue[T]) DequeueContext(ctx context.Context) (val T, err error) {
+	if err := ctx.Err(); err != nil {
+		return val, err
+	}
+
+	if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.cond.Broadcast()
+		})
+		defer stop()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		if q.isClosed {
+			return val, ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return val, err
+		}
+
+		if q.items.Len() > 0 {
+			wait := time.Until(q.items.items[0].readyAt)
+			if wait <= 0 {
+				return heap.Pop(q.items).(delayedItem[T]).value, nil
+			}
+			q.waitLocked(wait)
+			continue
+		}
+
+		q.cond.Wait()
+	}
+}
+
+// DequeueTimeout removes the earliest ready item from the queue, similar to DequeueContext.
+//
+// returns context.DeadlineExceeded if no item is ready within the given timeout, or ErrClosed if the queue is closed.
+func (q *DelayQueue[T]) DequeueTimeout(timeout time.Duration) (val T, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.DequeueContext(ctx)
+}
+
+// TryDequeue removes the earliest ready item from the queue, similar to Dequeue.
+//
+// However, TryDequeue does NOT block if no item is ready. It returns second value as false immediately if no item is ready or the queue is closed.
+func (q *DelayQueue[T]) TryDequeue() (val T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.isClosed || q.items.Len() == 0 || time.Now().Before(q.items.items[0].readyAt) {
+		return val, false
+	}
+
+	return heap.Pop(q.items).(delayedItem[T]).value, true
+}
+
+// NextReadyAt returns the ready-at time of the earliest item.
+// returns second value as false if the queue is empty or closed.
+func (q *DelayQueue[T]) NextReadyAt() (readyAt time.Time, ok bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.isClosed || q.items.Len() == 0 {
+		return readyAt, false
+	}
+
+	return q.items.items[0].readyAt, true
+}
+
+// IsEmpty returns true if the queue is empty.
+func (q *DelayQueue[T]) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.items.Len() == 0
+}
+
+// Len returns the length of the queue, including the items that are not ready yet.
+func (q *DelayQueue[T]) Len() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.items.Len()
+}
+
+// Clear removes all items from the queue and returns the size of the removed items.
+func (q *DelayQueue[T]) Clear() (size int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	size = q.items.Len()
+
+	// clean up the slice without changing the capacity and allocation.
+	clear(q.items.items)
+	q.items.items = q.items.items[:0:cap(q.items.items)]
+
+	return size
+}
+
+// waitLocked waits until the duration has passed or the queue is changed. the caller must hold the lock.
+func (q *DelayQueue[T]) waitLocked(d time.Duration) {
+	timer := time.AfterFunc(d, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	q.cond.Wait()
+}
@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDelayEnqueueDequeue(t *testing.T) {
+	q := NewDelay[string]()
+	now := time.Now()
+
+	q.Enqueue("C", now.Add(300*time.Millisecond))
+	q.Enqueue("A", now.Add(100*time.Millisecond))
+	q.Enqueue("B", now.Add(200*time.Millisecond))
+
+	if _, ok := q.TryDequeue(); ok {
+		t.Errorf("should not dequeue an item that is not ready")
+	}
+	if readyAt, ok := q.NextReadyAt(); !ok || !readyAt.Equal(now.Add(100*time.Millisecond)) {
+		t.Errorf("next ready-at should be the earliest item, actual: %v", readyAt)
+	}
+
+	for i, expected := range []string{"A", "B", "C"} {
+		actual, ok := q.Dequeue()
+		if !ok {
+			t.Errorf("item should be dequeued")
+		}
+		if expected != actual {
+			t.Errorf("item should be equal to the expected value, expected: %s, actual: %s", expected, actual)
+		}
+		if minReadyAt := now.Add(time.Duration(i+1) * 100 * time.Millisecond); time.Now().Before(minReadyAt) {
+			t.Errorf("item should not be dequeued before it's ready")
+		}
+	}
+}
+
+func TestDelayEnqueueEarlier(t *testing.T) {
+	q := NewDelay[string]()
+	q.EnqueueAfter("late", time.Hour)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.EnqueueAfter("early", 50*time.Millisecond)
+	}()
+
+	// the waiter should wake up for the earlier item instead of sleeping for an hour.
+	actual, err := q.DequeueTimeout(time.Second)
+	if err != nil {
+		t.Errorf("item should be dequeued, got error: %v", err)
+	}
+	if actual != "early" {
+		t.Errorf("item should be equal to the expected value, expected: %s, actual: %s", "early", actual)
+	}
+}
+
+func TestDelaySameReadyAt(t *testing.T) {
+	q := NewDelay[int]()
+	readyAt := time.Now()
+	for i := 0; i < 10; i++ {
+		q.Enqueue(i, readyAt)
+	}
+	for expected := 0; expected < 10; expected++ {
+		if actual, ok := q.TryDequeue(); !ok || expected != actual {
+			t.Errorf("items with the same ready-at should be dequeued in enqueue order, expected: %d, actual: %d", expected, actual)
+		}
+	}
+}
+
+func TestDelayContextAndClose(t *testing.T) {
+	q := NewDelay[int]()
+	q.EnqueueAfter(1, time.Hour)
+
+	if _, err := q.DequeueTimeout(100 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should be context.DeadlineExceeded, actual: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, ok := q.Dequeue(); ok {
+			t.Error("should not dequeue from a closed queue")
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.Close()
+	wg.Wait()
+
+	if q.EnqueueAfter(2, 0) {
+		t.Errorf("should not enqueue to a closed queue")
+	}
+}
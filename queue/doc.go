@@ -5,7 +5,7 @@ It's alternative way to communicate between goroutines compared to `channel`.
 The implementation of this in-memory message queue uses sync.Cond instead of channel.
 
 This queue is low-level and simple library, it's not a full-featured message queue.
-If your use-case requires a limited-size queue and blocking enqueue, please use a channel instead.
+If your use-case requires a limited-size queue, use NewBounded with an OverflowPolicy (block, drop newest, drop oldest or error).
 For advanced use-cases like distributed queue, persistent message please use a message broker like Kafka, RabbitMQ, NATES or NSQ instead.
 
 You can build any advanced message queue on top of this queue (use this queue for under the hood)
@@ -30,6 +30,16 @@ Queue is unlimited capacity, so you can enqueue as many as you want without bloc
 	  q.Enqueue("Foo")
 	}
 
+Bounded queue applies the overflow policy when the queue is full:
+
+	q := NewBounded[string](1000, OverflowDropOldest)
+	q.Enqueue("Foo") // discards the oldest item if the queue is full
+
+	q = NewBounded[string](1000, OverflowError)
+	if _, err := q.EnqueueContext(ctx, "Foo"); errors.Is(err, ErrFull) {
+	  // handle backpressure
+	}
+
 # Concurrency usage
 
 		q := New[string]()
@@ -50,5 +60,85 @@ Queue is unlimited capacity, so you can enqueue as many as you want without bloc
 		}
 
 	  q.Close() // close queue to stop goroutine
+
+Close discards the pending items, use Shutdown (or CloseWithDrain) to deliver the pending items to consumers before closing:
+
+	if err := q.Shutdown(ctx); err != nil {
+	  // ctx is done before all pending items are dequeued
+	}
+	<-q.Done() // closed when the queue is fully drained
+
+Use All to range over the queue until it's closed, or Chan to use the queue in a select statement:
+
+	for item := range q.All() {
+	  fmt.Println(item)
+	}
+
+	ch := q.Chan(ctx)
+	for {
+	  select {
+	  case item, ok := <-ch:
+	    ...
+	  case <-ticker.C:
+	    ...
+	  }
+	}
+
+# Priority queue usage
+
+PriorityQueue dequeues items in order of the given less function, with the same blocking semantics as Queue:
+
+	q := NewPriority(func(a, b Job) bool {
+	  return a.Priority > b.Priority // higher priority first
+	})
+	q.Enqueue(Job{Name: "backfill", Priority: 1})
+	q.Enqueue(Job{Name: "resync", Priority: 10})
+
+	job, _ := q.Dequeue()
+	fmt.Println(job.Name) // Output: resync
+
+# Delay queue usage
+
+DelayQueue dequeues items only after their ready-at time has passed, e.g. for retry with backoff:
+
+	q := NewDelay[Job]()
+	q.EnqueueAfter(job, 5*time.Second)
+
+	job, _ := q.Dequeue() // blocks until the job is ready
+
+# Broadcast usage
+
+Broadcaster fans out published items to all subscribers, each subscriber gets its own Queue.
+Topics does the same for multiple topics:
+
+	topics := NewTopics[string, Block]()
+	sub := topics.Subscribe("new-block")
+	defer topics.Unsubscribe("new-block", sub)
+
+	go func() {
+	  for {
+	    block, ok := sub.Dequeue()
+	    if !ok {
+	      return
+	    }
+	    fmt.Println(block)
+	  }
+	}()
+
+	topics.Publish("new-block", block)
+
+# Cancellation usage
+
+Use DequeueContext or DequeueTimeout to stop waiting when the context is done or the timeout is reached:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	item, err := q.DequeueContext(ctx)
+	if err != nil {
+	  // err is ctx.Err() or ErrClosed
+	  return err
+	}
+	fmt.Println(item)
 */
 package queue
@@ -0,0 +1,81 @@
+package queue
+
+import (
+	"context"
+)
+
+// CloseWithDrain stops accepting new items but keeps delivering the pending items to consumers.
+// Once all pending items are dequeued, the queue is closed and Dequeue returns second value as false.
+//
+// Enqueue returns -1 (EnqueueContext returns ErrClosed) after this method is called.
+// If the queue is already empty, the queue is closed immediately.
+func (q *Queue[T]) CloseWithDrain() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.isClosed || q.isDraining {
+		return
+	}
+
+	q.isDraining = true
+	if q.items.len() == 0 {
+		q.closeLocked()
+		return
+	}
+
+	// wake up blocked producers of a bounded queue to reject their items.
+	q.notFull.Broadcast()
+}
+
+// Shutdown gracefully closes the queue, similar to CloseWithDrain, and waits until all pending items are dequeued.
+//
+// returns ctx.Err() if the context is done before the queue is fully drained.
+// The queue keeps draining in that case, call Close to discard the remaining items.
+func (q *Queue[T]) Shutdown(ctx context.Context) error {
+	q.CloseWithDrain()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done returns a channel that is closed when the queue is closed,
+// either by Close or by dequeuing all pending items after CloseWithDrain or Shutdown.
+func (q *Queue[T]) Done() <-chan struct{} {
+	return q.done
+}
+
+// closeLocked closes the queue and discards all pending items. the caller must hold the lock.
+func (q *Queue[T]) closeLocked() {
+	if q.isClosed {
+		return
+	}
+
+	q.isClosed = true
+	q.items.release()
+	q.cond.Broadcast()
+	q.notFull.Broadcast()
+	close(q.done)
+}
+
+// removedLocked notifies that n items are removed from the queue. the caller must hold the lock.
+// It wakes up blocked producers and closes the queue if it's fully drained.
+func (q *Queue[T]) removedLocked(n int) {
+	if n <= 0 {
+		return
+	}
+
+	if q.isDraining && q.items.len() == 0 {
+		q.closeLocked()
+		return
+	}
+
+	if n == 1 {
+		q.notFull.Signal()
+	} else {
+		q.notFull.Broadcast()
+	}
+}
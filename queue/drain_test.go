@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCloseWithDrain(t *testing.T) {
+	q := New[int]()
+	q.EnqueueMany(1, 2, 3)
+	q.CloseWithDrain()
+
+	if index := q.Enqueue(4); index != -1 {
+		t.Errorf("should not enqueue to a draining queue")
+	}
+	if q.IsClosed() {
+		t.Errorf("queue should not be closed until drained")
+	}
+
+	for _, expected := range []int{1, 2, 3} {
+		actual, ok := q.Dequeue()
+		if !ok || expected != actual {
+			t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", expected, actual)
+		}
+	}
+
+	select {
+	case <-q.Done():
+	default:
+		t.Errorf("done channel should be closed when drained")
+	}
+	if !q.IsClosed() {
+		t.Errorf("queue should be closed when drained")
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Errorf("should not dequeue from a drained queue")
+	}
+}
+
+func TestCloseWithDrainEmpty(t *testing.T) {
+	var (
+		q  = New[int]()
+		wg sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, ok := q.Dequeue(); ok {
+			t.Error("should not dequeue from an empty queue")
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.CloseWithDrain()
+	wg.Wait()
+
+	if !q.IsClosed() {
+		t.Errorf("empty queue should be closed immediately")
+	}
+}
+
+func TestCloseWithDrainBlockedProducer(t *testing.T) {
+	q := NewBounded[int](1, OverflowBlock)
+	q.Enqueue(1)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := q.EnqueueContext(context.Background(), 2)
+		errCh <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.CloseWithDrain()
+
+	if err := <-errCh; !errors.Is(err, ErrClosed) {
+		t.Errorf("error should be ErrClosed, actual: %v", err)
+	}
+	if item, ok := q.Dequeue(); !ok || item != 1 {
+		t.Errorf("pending item should be dequeued, actual: %d", item)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	q := New[int]()
+	q.EnqueueMany(1, 2, 3)
+
+	received := make(chan int, 3)
+	go func() {
+		for item := range q.All() {
+			time.Sleep(10 * time.Millisecond)
+			received <- item
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := q.Shutdown(ctx); err != nil {
+		t.Errorf("queue should be drained, got error: %v", err)
+	}
+	if len(received) < 2 {
+		t.Errorf("pending items should be delivered, received: %d", len(received))
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	q := New[int]()
+	q.EnqueueMany(1, 2, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := q.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should be context.DeadlineExceeded, actual: %v", err)
+	}
+	if q.Len() != 3 {
+		t.Errorf("pending items should be kept, actual: %d", q.Len())
+	}
+
+	q.Close()
+	select {
+	case <-q.Done():
+	default:
+		t.Errorf("done channel should be closed after close")
+	}
+}
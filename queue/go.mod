@@ -1,3 +1,3 @@
 module github.com/Cleverse/go-utilities/queue
 
-go 1.18
+go 1.23
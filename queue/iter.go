@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"context"
+	"iter"
+)
+
+// Chan returns a receive-only channel that receives items dequeued from the queue.
+// It allows the queue to be used in a select statement with other channels.
+//
+// A pump goroutine dequeues items and sends them to the channel. The channel is closed
+// when the queue is closed or the context is done, so ranging over the channel terminates.
+//
+// NOTE: an item already dequeued by the pump goroutine is dropped if the context is done before it's received.
+func (q *Queue[T]) Chan(ctx context.Context) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for {
+			item, err := q.DequeueContext(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// All returns an iterator that dequeues items from the queue.
+// The iterator blocks(wait) until an item is available and stops when the queue is closed.
+//
+//	for item := range q.All() {
+//	  fmt.Println(item)
+//	}
+func (q *Queue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			item, ok := q.Dequeue()
+			if !ok || !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// AllContext returns an iterator that dequeues items from the queue, similar to All.
+// The iterator also stops when the context is done.
+func (q *Queue[T]) AllContext(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			item, err := q.DequeueContext(ctx)
+			if err != nil || !yield(item) {
+				return
+			}
+		}
+	}
+}
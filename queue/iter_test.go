@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChan(t *testing.T) {
+	q := New[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := q.Chan(ctx)
+	q.EnqueueMany(1, 2, 3)
+
+	for _, expected := range []int{1, 2, 3} {
+		select {
+		case actual := <-ch:
+			if expected != actual {
+				t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", expected, actual)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("item should be received from the channel")
+		}
+	}
+
+	q.Close()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("channel should be closed when the queue is closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("channel should be closed when the queue is closed")
+	}
+}
+
+func TestChanContextDone(t *testing.T) {
+	q := New[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := q.Chan(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("channel should be closed when the context is done")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("channel should be closed when the context is done")
+	}
+}
+
+func TestAll(t *testing.T) {
+	q := New[int]()
+	go func() {
+		q.EnqueueMany(1, 2, 3)
+		time.Sleep(100 * time.Millisecond)
+		q.Close()
+	}()
+
+	var actual []int
+	for item := range q.All() {
+		actual = append(actual, item)
+	}
+	if expected := []int{1, 2, 3}; !equalItems(expected, actual) {
+		t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", expected, actual)
+	}
+
+	// break should stop the iterator without consuming more items.
+	q = New[int]()
+	q.EnqueueMany(1, 2, 3)
+	for item := range q.All() {
+		if item == 2 {
+			break
+		}
+	}
+	if q.Len() != 1 {
+		t.Errorf("remaining items should be in the queue, actual: %d", q.Len())
+	}
+}
+
+func TestAllContext(t *testing.T) {
+	q := New[int]()
+	q.Enqueue(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	count := 0
+	for range q.AllContext(ctx) {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("iterator should stop when the context is done, count: %d", count)
+	}
+}
@@ -0,0 +1,223 @@
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// PriorityQueue a instance of thread-safe and unlimited-size generics in-memory priority queue.
+// Items are dequeued in order of the given less function (the least item first),
+// with the same blocking semantics as Queue.
+type PriorityQueue[T any] struct {
+	cond     *sync.Cond
+	items    *priorityItems[T]
+	mu       sync.RWMutex
+	isClosed bool
+}
+
+// NewPriority creates a new priority queue.
+// less reports whether item a should be dequeued before item b.
+func NewPriority[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	q := &PriorityQueue[T]{
+		items: &priorityItems[T]{
+			items: make([]T, 0),
+			less:  less,
+		},
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Close closes the queue.
+// queue will be permanent unusable after this method is called.
+func (q *PriorityQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.isClosed = true
+	q.items.items = nil
+	q.cond.Broadcast()
+}
+
+// IsClosed returns true if the queue is closed.
+func (q *PriorityQueue[T]) IsClosed() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.isClosed
+}
+
+// Enqueue adds an item to the queue. returns false if the queue is closed.
+func (q *PriorityQueue[T]) Enqueue(item T) (ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.isClosed {
+		return false
+	}
+
+	heap.Push(q.items, item)
+	q.cond.Signal()
+
+	return true
+}
+
+// Dequeue removes the highest priority item from the queue.
+//
+// If the queue is empty, this method blocks(wait) until an item is available.
+//
+// returns second value as false if the queue is closed.
+func (q *PriorityQueue[T]) Dequeue() (val T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.items.Len() == 0 {
+		if q.isClosed {
+			return val, false
+		}
+		q.cond.Wait()
+	}
+
+	if q.isClosed {
+		return val, false
+	}
+
+	return heap.Pop(q.items).(T), true
+}
+
+// DequeueContext removes the highest priority item from the queue, similar to Dequeue.
+//
+// If the queue is empty, this method blocks(wait) until an item is available or the context is done.
+//
+// returns ctx.Err() if the context is done before an item is available, or ErrClosed if the queue is closed.
+func (q *PriorityQueue[T]) DequeueContext(ctx context.Context) (val T, err error) {
+	if err := ctx.Err(); err != nil {
+		return val, err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.items.Len() == 0 {
+		if q.isClosed {
+			return val, ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return val, err
+		}
+		q.cond.Wait()
+	}
+
+	if q.isClosed {
+		return val, ErrClosed
+	}
+
+	return heap.Pop(q.items).(T), nil
+}
+
+// DequeueTimeout removes the highest priority item from the queue, similar to DequeueContext.
+//
+// returns context.DeadlineExceeded if no item is available within the given timeout, or ErrClosed if the queue is closed.
+func (q *PriorityQueue[T]) DequeueTimeout(timeout time.Duration) (val T, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.DequeueContext(ctx)
+}
+
+// TryDequeue removes the highest priority item from the queue, similar to Dequeue.
+//
+// However, TryDequeue does NOT block if the queue is empty. It returns second value as false immediately if the queue is empty or closed.
+func (q *PriorityQueue[T]) TryDequeue() (val T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.isClosed || q.items.Len() == 0 {
+		return val, false
+	}
+
+	return heap.Pop(q.items).(T), true
+}
+
+// Peek returns the highest priority item without removing it from the queue.
+// returns second value as false if the queue is empty or closed.
+func (q *PriorityQueue[T]) Peek() (val T, ok bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.isClosed || q.items.Len() == 0 {
+		return val, false
+	}
+
+	return q.items.items[0], true
+}
+
+// IsEmpty returns true if the queue is empty.
+func (q *PriorityQueue[T]) IsEmpty() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.items.Len() == 0
+}
+
+// Len returns the length of the queue.
+func (q *PriorityQueue[T]) Len() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.items.Len()
+}
+
+// Items returns a copy of the all items in the queue.
+// The items are in heap order, NOT in dequeue order.
+func (q *PriorityQueue[T]) Items() []T {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	items := make([]T, q.items.Len())
+	copy(items, q.items.items)
+	return items
+}
+
+// Clear removes all items from the queue and returns the size of the removed items.
+func (q *PriorityQueue[T]) Clear() (size int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	size = q.items.Len()
+
+	// clean up the slice without changing the capacity and allocation.
+	clear(q.items.items)
+	q.items.items = q.items.items[:0:cap(q.items.items)]
+
+	return size
+}
+
+// priorityItems implements heap.Interface.
+type priorityItems[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (p *priorityItems[T]) Len() int           { return len(p.items) }
+func (p *priorityItems[T]) Less(i, j int) bool { return p.less(p.items[i], p.items[j]) }
+func (p *priorityItems[T]) Swap(i, j int)      { p.items[i], p.items[j] = p.items[j], p.items[i] }
+
+func (p *priorityItems[T]) Push(x any) {
+	p.items = append(p.items, x.(T))
+}
+
+func (p *priorityItems[T]) Pop() any {
+	n := len(p.items)
+	item := p.items[n-1]
+
+	// release the reference of the popped item for garbage collection.
+	var zero T
+	p.items[n-1] = zero
+	p.items = p.items[:n-1]
+
+	return item
+}
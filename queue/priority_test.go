@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testJob struct {
+	name     string
+	priority int
+}
+
+func TestPriorityEnqueueDequeue(t *testing.T) {
+	q := NewPriority(func(a, b testJob) bool {
+		return a.priority > b.priority
+	})
+
+	q.Enqueue(testJob{name: "backfill-1", priority: 1})
+	q.Enqueue(testJob{name: "resync", priority: 10})
+	q.Enqueue(testJob{name: "backfill-2", priority: 1})
+	q.Enqueue(testJob{name: "urgent", priority: 100})
+
+	if item, ok := q.Peek(); !ok || item.name != "urgent" {
+		t.Errorf("peek item should be the highest priority, actual: %v", item)
+	}
+
+	expecteds := []int{100, 10, 1, 1}
+	for _, expected := range expecteds {
+		actual, ok := q.Dequeue()
+		if !ok {
+			t.Errorf("item should be dequeued")
+		}
+		if expected != actual.priority {
+			t.Errorf("priority should be equal to the expected value, expected: %d, actual: %d", expected, actual.priority)
+		}
+	}
+
+	if _, ok := q.TryDequeue(); ok {
+		t.Errorf("should not dequeue from an empty queue")
+	}
+}
+
+func TestPriorityAsyncDequeue(t *testing.T) {
+	q := NewPriority(func(a, b int) bool { return a < b })
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		q.Enqueue(1)
+	}()
+
+	// expected to block until enqueue
+	actual, ok := q.Dequeue()
+	if !ok || actual != 1 {
+		t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", 1, actual)
+	}
+
+	if _, err := q.DequeueTimeout(100 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should be context.DeadlineExceeded, actual: %v", err)
+	}
+}
+
+func TestPriorityClose(t *testing.T) {
+	var (
+		q  = NewPriority(func(a, b int) bool { return a < b })
+		wg sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, ok := q.Dequeue(); ok {
+			t.Error("should not dequeue from an empty queue")
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.Close()
+	wg.Wait()
+
+	if q.Enqueue(1) {
+		t.Errorf("should not enqueue to a closed queue")
+	}
+	if _, err := q.DequeueContext(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("error should be ErrClosed, actual: %v", err)
+	}
+}
+
+func TestPriorityClear(t *testing.T) {
+	q := NewPriority(func(a, b int) bool { return a < b })
+	for i := 0; i < 10; i++ {
+		q.Enqueue(i)
+	}
+
+	if actual := q.Clear(); actual != 10 {
+		t.Errorf("cleared size should be equal to the enqueued size, expected: %d, actual: %d", 10, actual)
+	}
+	if !q.IsEmpty() {
+		t.Errorf("queue should be empty")
+	}
+}
@@ -1,36 +1,63 @@
 package queue
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed is returned when operating on a closed queue.
+	ErrClosed = errors.New("queue is closed")
+
+	// ErrFull is returned when enqueue to a full bounded queue with OverflowError policy.
+	ErrFull = errors.New("queue is full")
 )
 
 // Queue a instance of thread-safe and unlimited-size generics in-memory message queue
 // and alternative way to communicate between goroutines compared to channel.
 type Queue[T comparable] struct {
 	cond     *sync.Cond
-	items    []T
+	notFull  *sync.Cond
+	items    ring[T]
 	mu       sync.RWMutex
 	isClosed bool
+
+	// isDraining is true when the queue rejects enqueues but still delivers the remaining items.
+	isDraining bool
+	done       chan struct{}
+
+	// capacity is the maximum number of items, 0 means unlimited.
+	capacity int
+	policy   OverflowPolicy
 }
 
 // New creates a new message queue.
 func New[T comparable]() *Queue[T] {
+	return newQueue[T](0, OverflowBlock)
+}
+
+func newQueue[T comparable](capacity int, policy OverflowPolicy) *Queue[T] {
 	q := &Queue[T]{
-		items: make([]T, 0),
+		capacity: capacity,
+		policy:   policy,
+		done:     make(chan struct{}),
 	}
 	q.cond = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
 	return q
 }
 
-// Close closes the queue.
+// Close closes the queue and discards all pending items.
 // queue will be permanent unusable after this method is called.
+//
+// Use CloseWithDrain or Shutdown instead to deliver the pending items before closing.
 func (q *Queue[T]) Close() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	q.isClosed = true
-	q.items = nil
-	q.cond.Broadcast()
+	q.closeLocked()
 }
 
 // IsClosed returns true if the queue is closed.
@@ -41,18 +68,62 @@ func (q *Queue[T]) IsClosed() bool {
 }
 
 // Enqueue adds an item to the end of the queue. returns the index of the item.
+//
+// If the queue is bounded and full, the overflow policy is applied (see OverflowPolicy).
+// returns -1 if the queue is closed or the item is rejected by the overflow policy.
 func (q *Queue[T]) Enqueue(item T) (index int) {
+	index, _ = q.EnqueueContext(context.Background(), item)
+	return index
+}
+
+// EnqueueContext adds an item to the end of the queue, similar to Enqueue.
+//
+// If the queue is bounded and full with OverflowBlock policy, this method blocks(wait) until space is available or the context is done.
+//
+// returns ErrClosed if the queue is closed, ErrFull if the queue is full with OverflowError policy,
+// or ctx.Err() if the context is done before space is available.
+func (q *Queue[T]) EnqueueContext(ctx context.Context, item T) (index int, err error) {
+	if q.capacity > 0 && q.policy == OverflowBlock && ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.notFull.Broadcast()
+		})
+		defer stop()
+	}
+
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if q.isClosed {
-		return -1
+	return q.enqueueLocked(ctx, item)
+}
+
+// enqueueLocked adds an item to the end of the queue and applies the overflow policy. the caller must hold the lock.
+func (q *Queue[T]) enqueueLocked(ctx context.Context, item T) (index int, err error) {
+	for !q.isClosed && !q.isDraining && q.capacity > 0 && q.items.len() >= q.capacity {
+		switch q.policy {
+		case OverflowDropNewest:
+			return -1, nil
+		case OverflowDropOldest:
+			q.items.pop()
+		case OverflowError:
+			return -1, ErrFull
+		default:
+			if err := ctx.Err(); err != nil {
+				return -1, err
+			}
+			q.notFull.Wait()
+		}
 	}
 
-	q.items = append(q.items, item)
+	if q.isClosed || q.isDraining {
+		return -1, ErrClosed
+	}
+
+	q.items.push(item)
 	q.cond.Signal()
 
-	return len(q.items) - 1
+	return q.items.len() - 1, nil
 }
 
 // Dequeue removes an item from the front of the queue.
@@ -64,7 +135,7 @@ func (q *Queue[T]) Dequeue() (val T, ok bool) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	for len(q.items) == 0 {
+	for q.items.len() == 0 {
 		if q.isClosed {
 			return val, false
 		}
@@ -73,16 +144,67 @@ func (q *Queue[T]) Dequeue() (val T, ok bool) {
 
 	// Recheck the length of the queue after waking up from wait.
 	// or another goroutine might have dequeued the last item.
-	if q.isClosed || len(q.items) == 0 {
+	if q.isClosed || q.items.len() == 0 {
 		return val, false
 	}
 
-	item := q.items[0]
-	q.items = q.items[1:]
+	item := q.items.pop()
+	q.removedLocked(1)
 
 	return item, true
 }
 
+// DequeueContext removes an item from the front of the queue, similar to Dequeue.
+//
+// If the queue is empty, this method blocks(wait) until an item is available or the context is done.
+//
+// returns ctx.Err() if the context is done before an item is available, or ErrClosed if the queue is closed.
+func (q *Queue[T]) DequeueContext(ctx context.Context) (val T, err error) {
+	if err := ctx.Err(); err != nil {
+		return val, err
+	}
+
+	// Wake up all waiters when the context is done, so this waiter can check ctx.Err().
+	// The callback must hold the lock to avoid broadcasting between ctx.Err() check and cond.Wait().
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.items.len() == 0 {
+		if q.isClosed {
+			return val, ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return val, err
+		}
+		q.cond.Wait()
+	}
+
+	if q.isClosed {
+		return val, ErrClosed
+	}
+
+	item := q.items.pop()
+	q.removedLocked(1)
+
+	return item, nil
+}
+
+// DequeueTimeout removes an item from the front of the queue, similar to DequeueContext.
+//
+// returns context.DeadlineExceeded if no item is available within the given timeout, or ErrClosed if the queue is closed.
+func (q *Queue[T]) DequeueTimeout(timeout time.Duration) (val T, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.DequeueContext(ctx)
+}
+
 // TryDequeue removes an item from the front of the queue, similar to Dequeue.
 //
 // However, TryDequeue does NOT block if the queue is empty. It returns second value as false immediately if the queue is empty or closed.
@@ -90,12 +212,12 @@ func (q *Queue[T]) TryDequeue() (val T, ok bool) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if q.isClosed || len(q.items) == 0 {
+	if q.isClosed || q.items.len() == 0 {
 		return val, false
 	}
 
-	item := q.items[0]
-	q.items = q.items[1:]
+	item := q.items.pop()
+	q.removedLocked(1)
 
 	return item, true
 }
@@ -104,7 +226,7 @@ func (q *Queue[T]) TryDequeue() (val T, ok bool) {
 func (q *Queue[T]) IsEmpty() bool {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
-	return len(q.items) == 0
+	return q.items.len() == 0
 }
 
 // IndexOf returns the index of the first item that matches the target.
@@ -119,8 +241,8 @@ func (q *Queue[T]) IndexOf(target T) (index int) {
 func (q *Queue[T]) IndexOfIter(cb func(item T) bool) (index int) {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
-	for i, item := range q.items {
-		if cb(item) {
+	for i := 0; i < q.items.len(); i++ {
+		if cb(q.items.at(i)) {
 			return i
 		}
 	}
@@ -131,15 +253,15 @@ func (q *Queue[T]) IndexOfIter(cb func(item T) bool) (index int) {
 func (q *Queue[T]) Len() int {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
-	return len(q.items)
+	return q.items.len()
 }
 
 // Items returns a copy of the all items in the queue.
 func (q *Queue[T]) Items() []T {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
-	items := make([]T, len(q.items))
-	copy(items, q.items)
+	items := make([]T, q.items.len())
+	q.items.copyTo(items)
 	return items
 }
 
@@ -147,18 +269,28 @@ func (q *Queue[T]) Items() []T {
 func (q *Queue[T]) RemoveAt(index int) (size int) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.items = append(q.items[:index], q.items[index+1:]...)
-	return len(q.items)
+	q.items.removeAt(index)
+	q.removedLocked(1)
+	return q.items.len()
 }
 
 // Clear removes all items from the queue and returns the size of the removed items.
 func (q *Queue[T]) Clear() (size int) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	size = len(q.items)
+	size = q.items.len()
 
-	// clean up the slice without changing the capacity and allocation.
-	q.items = q.items[:0:cap(q.items)]
+	// clean up the buffer without changing the capacity and allocation.
+	q.items.clear()
+	q.removedLocked(size)
 
 	return size
 }
+
+// Shrink releases the unused buffer memory of the queue, e.g. after a burst of enqueues.
+// It's useful to call this method when the queue is idle. returns true if the buffer is reallocated.
+func (q *Queue[T]) Shrink() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.shrink()
+}
@@ -1,6 +1,9 @@
 package queue
 
 import (
+	"context"
+	"errors"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -90,7 +93,7 @@ func TestClear(t *testing.T) {
 		q.Enqueue(i)
 	}
 
-	itemsCap := cap(q.items)
+	itemsCap := q.items.cap()
 	actualSize := q.Clear()
 
 	if q.Len() != 0 {
@@ -99,7 +102,7 @@ func TestClear(t *testing.T) {
 	if expectedSize != actualSize {
 		t.Errorf("flushed size should be equal to the enqueued size")
 	}
-	if itemsCap != cap(q.items) {
+	if itemsCap != q.items.cap() {
 		t.Errorf("capacity should not change after flush")
 	}
 }
@@ -141,3 +144,131 @@ func TestRemoveAt(t *testing.T) {
 		}
 	}
 }
+
+func TestDequeueContext(t *testing.T) {
+	expected := "A"
+	q := New[string]()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		q.Enqueue(expected)
+	}()
+
+	actual, err := q.DequeueContext(context.Background())
+	if err != nil {
+		t.Errorf("item should be dequeued, got error: %v", err)
+	}
+	if expected != actual {
+		t.Errorf("item should be equal to the expected value, expected: %s, actual: %s", expected, actual)
+	}
+}
+
+func TestDequeueContextCancel(t *testing.T) {
+	q := New[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	if _, err := q.DequeueContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("error should be context.Canceled, actual: %v", err)
+	}
+
+	// already cancelled context should return immediately, even if the queue has items.
+	q.Enqueue(1)
+	if _, err := q.DequeueContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("error should be context.Canceled, actual: %v", err)
+	}
+	if q.Len() != 1 {
+		t.Errorf("item should not be dequeued with a cancelled context")
+	}
+}
+
+func TestDequeueTimeout(t *testing.T) {
+	q := New[int]()
+
+	start := time.Now()
+	if _, err := q.DequeueTimeout(100 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should be context.DeadlineExceeded, actual: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("should wait until timeout, elapsed: %v", elapsed)
+	}
+
+	q.Enqueue(1)
+	actual, err := q.DequeueTimeout(100 * time.Millisecond)
+	if err != nil {
+		t.Errorf("item should be dequeued, got error: %v", err)
+	}
+	if actual != 1 {
+		t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", 1, actual)
+	}
+}
+
+func TestDequeueContextClose(t *testing.T) {
+	q := New[int]()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		q.Close()
+	}()
+
+	if _, err := q.DequeueContext(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("error should be ErrClosed, actual: %v", err)
+	}
+}
+
+func TestDequeueContextConcurrency(t *testing.T) {
+	var (
+		q         = New[int]()
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		consumers = 8
+		size      = 1000
+		received  = make(map[int]bool, size)
+	)
+
+	goroutines := runtime.NumGoroutine()
+
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				item, err := q.DequeueContext(ctx)
+				cancel()
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				received[item] = true
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < size; i++ {
+		q.Enqueue(i)
+		if i%100 == 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	for !q.IsEmpty() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	q.Close()
+	wg.Wait()
+
+	if len(received) != size {
+		t.Errorf("all items should be dequeued, expected: %d, actual: %d", size, len(received))
+	}
+
+	// wait for context callbacks to finish.
+	time.Sleep(50 * time.Millisecond)
+	if actual := runtime.NumGoroutine(); actual > goroutines {
+		t.Errorf("goroutines should not be leaked, expected: <= %d, actual: %d", goroutines, actual)
+	}
+}
@@ -0,0 +1,128 @@
+package queue
+
+// minRingCapacity is the initial buffer capacity of a ring when the first item is pushed.
+const minRingCapacity = 16
+
+// ring is a growable circular buffer. It's NOT thread-safe, the caller must hold the lock.
+//
+// Items are addressed by logical index from the front of the ring (0 is the next item to pop),
+// so the index semantics are the same as a slice regardless of the physical position in the buffer.
+type ring[T any] struct {
+	buf  []T
+	head int
+	size int
+}
+
+func (r *ring[T]) len() int {
+	return r.size
+}
+
+func (r *ring[T]) cap() int {
+	return len(r.buf)
+}
+
+// at returns the item at the given logical index.
+func (r *ring[T]) at(index int) T {
+	return r.buf[r.pos(index)]
+}
+
+// push adds an item to the end of the ring, grows the buffer if it's full.
+func (r *ring[T]) push(item T) {
+	if r.size == len(r.buf) {
+		r.resize(max(minRingCapacity, len(r.buf)*2))
+	}
+	r.buf[r.pos(r.size)] = item
+	r.size++
+}
+
+// pop removes and returns the item at the front of the ring. the ring must not be empty.
+func (r *ring[T]) pop() T {
+	var zero T
+	item := r.buf[r.head]
+
+	// release the reference of the popped item for garbage collection.
+	r.buf[r.head] = zero
+	r.head = r.pos(1)
+	r.size--
+	if r.size == 0 {
+		r.head = 0
+	}
+
+	return item
+}
+
+// removeAt removes the item at the given logical index by shifting the following items toward the front.
+func (r *ring[T]) removeAt(index int) {
+	if index < 0 || index >= r.size {
+		panic("queue: index out of range")
+	}
+
+	var zero T
+	for i := index; i < r.size-1; i++ {
+		r.buf[r.pos(i)] = r.buf[r.pos(i+1)]
+	}
+	r.buf[r.pos(r.size-1)] = zero
+	r.size--
+	if r.size == 0 {
+		r.head = 0
+	}
+}
+
+// clear removes all items without changing the capacity and allocation.
+func (r *ring[T]) clear() {
+	clear(r.buf)
+	r.head = 0
+	r.size = 0
+}
+
+// release removes all items and releases the buffer.
+func (r *ring[T]) release() {
+	r.buf = nil
+	r.head = 0
+	r.size = 0
+}
+
+// shrink reduces the buffer capacity to fit the items (but not lower than minRingCapacity).
+// returns true if the buffer is reallocated.
+func (r *ring[T]) shrink() bool {
+	capacity := max(minRingCapacity, r.size)
+	if r.size == 0 {
+		capacity = 0
+	}
+	if capacity >= len(r.buf) {
+		return false
+	}
+	r.resize(capacity)
+	return true
+}
+
+// copyTo copies all items in logical order to dst and returns the number of copied items.
+func (r *ring[T]) copyTo(dst []T) int {
+	if r.size == 0 {
+		return 0
+	}
+	end := r.head + r.size
+	if end <= len(r.buf) {
+		return copy(dst, r.buf[r.head:end])
+	}
+	n := copy(dst, r.buf[r.head:])
+	return n + copy(dst[n:], r.buf[:end-len(r.buf)])
+}
+
+func (r *ring[T]) resize(capacity int) {
+	var buf []T
+	if capacity > 0 {
+		buf = make([]T, capacity)
+		r.copyTo(buf)
+	}
+	r.buf = buf
+	r.head = 0
+}
+
+func (r *ring[T]) pos(index int) int {
+	p := r.head + index
+	if p >= len(r.buf) {
+		p -= len(r.buf)
+	}
+	return p
+}
@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"testing"
+)
+
+func TestRingWrapAround(t *testing.T) {
+	var r ring[int]
+
+	// move head to the middle of the buffer, then push until the items wrap around.
+	for i := 0; i < minRingCapacity; i++ {
+		r.push(i)
+	}
+	for i := 0; i < minRingCapacity/2; i++ {
+		r.pop()
+	}
+	for i := minRingCapacity; i < minRingCapacity+minRingCapacity/2; i++ {
+		r.push(i)
+	}
+
+	if r.cap() != minRingCapacity {
+		t.Errorf("capacity should not grow, expected: %d, actual: %d", minRingCapacity, r.cap())
+	}
+	for i := 0; i < r.len(); i++ {
+		if expected, actual := minRingCapacity/2+i, r.at(i); expected != actual {
+			t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", expected, actual)
+		}
+	}
+
+	// grow while wrapped around.
+	r.push(-1)
+	if r.cap() != minRingCapacity*2 {
+		t.Errorf("capacity should grow, expected: %d, actual: %d", minRingCapacity*2, r.cap())
+	}
+	items := make([]int, r.len())
+	r.copyTo(items)
+	for i, expected := range append(seq(minRingCapacity/2, minRingCapacity+minRingCapacity/2), -1) {
+		if expected != items[i] {
+			t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", expected, items[i])
+		}
+	}
+}
+
+func TestRingRemoveAt(t *testing.T) {
+	var r ring[int]
+	for i := 0; i < minRingCapacity; i++ {
+		r.push(i)
+	}
+	for i := 0; i < 10; i++ {
+		r.pop()
+	}
+	for i := minRingCapacity; i < minRingCapacity+5; i++ {
+		r.push(i)
+	}
+
+	// remove an item before the wrap-around point.
+	r.removeAt(2)
+	items := make([]int, r.len())
+	r.copyTo(items)
+	expecteds := []int{10, 11, 13, 14, 15, 16, 17, 18, 19, 20}
+	if !equalItems(expecteds, items) {
+		t.Errorf("items should be equal to the expected value, expected: %v, actual: %v", expecteds, items)
+	}
+}
+
+func TestRingShrink(t *testing.T) {
+	var r ring[int]
+	for i := 0; i < 1000; i++ {
+		r.push(i)
+	}
+	for i := 0; i < 990; i++ {
+		r.pop()
+	}
+
+	if !r.shrink() {
+		t.Errorf("buffer should be reallocated")
+	}
+	if r.cap() != minRingCapacity {
+		t.Errorf("capacity should be shrunk, expected: %d, actual: %d", minRingCapacity, r.cap())
+	}
+	for i := 0; i < r.len(); i++ {
+		if expected, actual := 990+i, r.at(i); expected != actual {
+			t.Errorf("item should be equal to the expected value, expected: %d, actual: %d", expected, actual)
+		}
+	}
+	if r.shrink() {
+		t.Errorf("buffer should not be reallocated when it already fits")
+	}
+}
+
+func seq(from, to int) []int {
+	items := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		items = append(items, i)
+	}
+	return items
+}
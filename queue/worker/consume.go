@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/Cleverse/go-utilities/logger"
+	"github.com/Cleverse/go-utilities/logger/slogx"
+	"github.com/Cleverse/go-utilities/queue"
+	"github.com/cockroachdb/errors"
+)
+
+// Handler processes an item dequeued by Consume.
+// Return an error wrapped with errs.Retryable to retry the item.
+type Handler[T any] func(ctx context.Context, item T) error
+
+// ConsumeConfig is the configuration of Consume.
+type ConsumeConfig struct {
+	// MaxRetries is the maximum number of retries for an item when the handler returns errs.Retryable error.
+	// Default is 3, negative value disables retry.
+	MaxRetries int
+
+	// RetryDelay is the delay before the first retry, the delay is doubled for each subsequent retry.
+	// Default is 100ms.
+	RetryDelay time.Duration
+
+	// MaxRetryDelay is the maximum delay between retries. Default is 10s.
+	MaxRetryDelay time.Duration
+}
+
+const (
+	defaultConsumeMaxRetries    = 3
+	defaultConsumeRetryDelay    = 100 * time.Millisecond
+	defaultConsumeMaxRetryDelay = 10 * time.Second
+)
+
+// Consume runs a worker pool of the given concurrency that dequeues items from the queue and processes them with the handler.
+//
+// Handler errors and panics are logged through the logger package and do not stop the workers.
+// If the handler returns an error wrapped with errs.Retryable, the item is retried with exponential backoff (see ConsumeConfig).
+//
+// Consume blocks until the queue is closed and drained (see queue.Queue.CloseWithDrain and queue.Queue.Shutdown), then returns nil.
+// returns ctx.Err() if the context is done before that.
+func Consume[T comparable](ctx context.Context, q *queue.Queue[T], concurrency int, handler Handler[T], config ...ConsumeConfig) error {
+	var cf ConsumeConfig
+	if len(config) > 0 {
+		cf = config[0]
+	}
+	if cf.MaxRetries == 0 {
+		cf.MaxRetries = defaultConsumeMaxRetries
+	}
+	if cf.RetryDelay <= 0 {
+		cf.RetryDelay = defaultConsumeRetryDelay
+	}
+	if cf.MaxRetryDelay <= 0 {
+		cf.MaxRetryDelay = defaultConsumeMaxRetryDelay
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx = logger.WithContext(ctx, slogx.String("package", "queue/worker"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			ctx := logger.WithContext(ctx, slogx.Int("worker", worker))
+			for {
+				item, err := q.DequeueContext(ctx)
+				if err != nil {
+					return
+				}
+				if err := handleWithRetry(ctx, item, handler, cf); err != nil {
+					logger.ErrorContext(ctx, "Failed to handle queue item", slogx.Error(err))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return errors.WithStack(ctx.Err())
+}
+
+// handleWithRetry calls the handler and retries it while the handler returns errs.Retryable error.
+func handleWithRetry[T any](ctx context.Context, item T, handler Handler[T], cf ConsumeConfig) error {
+	delay := cf.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := safeHandle(ctx, item, handler)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errs.Retryable) || attempt >= cf.MaxRetries {
+			return errors.Wrapf(err, "attempts: %d", attempt+1)
+		}
+
+		logger.WarnContext(ctx, "Retrying queue item",
+			slogx.Error(err),
+			slogx.Int("attempt", attempt+1),
+			slogx.Duration("delay", delay),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Wrap(err, "context done before retry")
+		case <-timer.C:
+		}
+		delay = min(delay*2, cf.MaxRetryDelay)
+	}
+}
+
+// safeHandle calls the handler and recovers panics into errors.
+func safeHandle[T any](ctx context.Context, item T, handler Handler[T]) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rErr, ok := r.(error); ok {
+				err = errors.Wrap(rErr, "handler panic")
+				return
+			}
+			err = errors.Newf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, item)
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Cleverse/go-utilities/errs"
+	"github.com/Cleverse/go-utilities/queue"
+	cerrors "github.com/cockroachdb/errors"
+)
+
+func TestConsume(t *testing.T) {
+	var (
+		q       = queue.New[int]()
+		mu      sync.Mutex
+		handled = make(map[int]bool)
+	)
+	for i := 0; i < 100; i++ {
+		q.Enqueue(i)
+	}
+	q.CloseWithDrain()
+
+	err := Consume(context.Background(), q, 4, func(ctx context.Context, item int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled[item] = true
+		return nil
+	})
+	if err != nil {
+		t.Errorf("consume should return nil when the queue is drained, got error: %v", err)
+	}
+	if len(handled) != 100 {
+		t.Errorf("all items should be handled, expected: %d, actual: %d", 100, len(handled))
+	}
+}
+
+func TestConsumeRetryAndPanic(t *testing.T) {
+	var (
+		q        = queue.New[string]()
+		attempts = make(map[string]*atomic.Int32)
+	)
+	for _, item := range []string{"ok", "retry", "fail", "panic"} {
+		attempts[item] = &atomic.Int32{}
+		q.Enqueue(item)
+	}
+	q.CloseWithDrain()
+
+	err := Consume(context.Background(), q, 2, func(ctx context.Context, item string) error {
+		attempt := attempts[item].Add(1)
+		switch item {
+		case "retry":
+			if attempt < 3 {
+				return cerrors.Wrap(errs.Retryable, "upstream unavailable")
+			}
+		case "fail":
+			return cerrors.New("permanent failure")
+		case "panic":
+			panic("boom")
+		}
+		return nil
+	}, ConsumeConfig{MaxRetries: 5, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Errorf("consume should return nil when the queue is drained, got error: %v", err)
+	}
+
+	expecteds := map[string]int32{"ok": 1, "retry": 3, "fail": 1, "panic": 1}
+	for item, expected := range expecteds {
+		if actual := attempts[item].Load(); expected != actual {
+			t.Errorf("attempts of %q should be equal to the expected value, expected: %d, actual: %d", item, expected, actual)
+		}
+	}
+}
+
+func TestConsumeContextDone(t *testing.T) {
+	q := queue.New[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := Consume(ctx, q, 2, func(ctx context.Context, item int) error {
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should be context.DeadlineExceeded, actual: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+/*
+Package worker runs a pool of workers that consume a github.com/Cleverse/go-utilities/queue.Queue.
+It's a separate module, so the queue package stays zero-dependency.
+
+Consume runs a pool of workers that dequeue and handle items until the queue is closed and drained.
+Handler errors and panics are logged, and errors wrapped with errs.Retryable are retried with backoff:
+
+	err := worker.Consume(ctx, q, 8, func(ctx context.Context, job Job) error {
+	  if err := process(ctx, job); err != nil {
+	    return errors.Mark(err, errs.Retryable) // retry this job
+	  }
+	  return nil
+	})
+*/
+package worker
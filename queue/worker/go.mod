@@ -0,0 +1,26 @@
+module github.com/Cleverse/go-utilities/queue/worker
+
+go 1.25
+
+require (
+	github.com/Cleverse/go-utilities/errs v0.0.0-20250808171844-1347aec4138e
+	github.com/Cleverse/go-utilities/logger v0.0.0-20250808171844-1347aec4138e
+	github.com/Cleverse/go-utilities/queue v0.0.0-20250808171844-1347aec4138e
+	github.com/cockroachdb/errors v1.12.0
+)
+
+require (
+	github.com/cockroachdb/logtags v0.0.0-20230118201751-21c54148d20b // indirect
+	github.com/cockroachdb/redact v1.1.5 // indirect
+	github.com/getsentry/sentry-go v0.27.0 // indirect
+	github.com/goccy/go-json v0.10.5 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/lmittmann/tint v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/rogpeppe/go-internal v1.13.1 // indirect
+	github.com/samber/lo v1.50.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
+)
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// CacheStore is a cache store backed by a redis client (e.g. a client created by New).
+// It implements github.com/Cleverse/go-utilities/httpclient.CacheStore interface.
+type CacheStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewCacheStore creates a cache store that stores the values in redis with the prefix prepended to the keys.
+func NewCacheStore(client redis.Cmdable, prefix string) *CacheStore {
+	return &CacheStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get returns the value of the key, or false if it's not found or expired.
+func (s *CacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, errors.WithStack(err)
+	}
+	return value, true, nil
+}
+
+// Set stores the value of the key for the ttl.
+func (s *CacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.client.Set(ctx, s.prefix+key, value, ttl).Err(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete removes the key.
+func (s *CacheStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// fakeRedis is an in-memory redis.Cmdable that implements only the commands used by CacheStore.
+type fakeRedis struct {
+	redis.Cmdable
+	values map[string][]byte
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		values: make(map[string][]byte),
+		ttls:   make(map[string]time.Duration),
+	}
+}
+
+func (f *fakeRedis) Get(_ context.Context, key string) *redis.StringCmd {
+	if f.err != nil {
+		return redis.NewStringResult("", f.err)
+	}
+	value, ok := f.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(string(value), nil)
+}
+
+func (f *fakeRedis) Set(_ context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	if f.err != nil {
+		return redis.NewStatusResult("", f.err)
+	}
+	f.values[key] = value.([]byte)
+	f.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeRedis) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	if f.err != nil {
+		return redis.NewIntResult(0, f.err)
+	}
+	var n int64
+	for _, key := range keys {
+		if _, ok := f.values[key]; ok {
+			delete(f.values, key)
+			delete(f.ttls, key)
+			n++
+		}
+	}
+	return redis.NewIntResult(n, nil)
+}
+
+func TestCacheStore(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRedis()
+	store := NewCacheStore(client, "httpclient:")
+
+	if _, ok, err := store.Get(ctx, "a"); ok || err != nil {
+		t.Errorf("Get of missing key = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	if err := store.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok := client.values["httpclient:a"]; !ok {
+		t.Errorf("Set() keys = %v, want prefixed key httpclient:a", client.values)
+	}
+	if ttl := client.ttls["httpclient:a"]; ttl != time.Minute {
+		t.Errorf("Set() ttl = %v, want %v", ttl, time.Minute)
+	}
+
+	value, ok, err := store.Get(ctx, "a")
+	if err != nil || !ok || !bytes.Equal(value, []byte("1")) {
+		t.Errorf("Get() = (%q, %v, %v), want (\"1\", true, nil)", value, ok, err)
+	}
+
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok, err := store.Get(ctx, "a"); ok || err != nil {
+		t.Errorf("Get of deleted key = (%v, %v), want (false, nil)", ok, err)
+	}
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Errorf("Delete of missing key error = %v", err)
+	}
+}
+
+func TestCacheStoreError(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRedis()
+	client.err = errors.New("connection refused")
+	store := NewCacheStore(client, "")
+
+	if _, _, err := store.Get(ctx, "a"); !errors.Is(err, client.err) {
+		t.Errorf("Get() error = %v, want %v", err, client.err)
+	}
+	if err := store.Set(ctx, "a", []byte("1"), time.Minute); !errors.Is(err, client.err) {
+		t.Errorf("Set() error = %v, want %v", err, client.err)
+	}
+	if err := store.Delete(ctx, "a"); !errors.Is(err, client.err) {
+		t.Errorf("Delete() error = %v, want %v", err, client.err)
+	}
+}